@@ -3,6 +3,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/shipengqi/errors/sets"
 )
@@ -50,18 +51,22 @@ func NewAggregate(errlist []error) Aggregate {
 type aggregate []error
 
 // Error is part of the error interface.
-func (agg aggregate) Error() string {
+func (agg aggregate) Error() string { return redact(agg.plainMessage()) }
+
+func (agg aggregate) writePlain(w io.Writer) { _, _ = io.WriteString(w, agg.plainMessage()) }
+
+func (agg aggregate) plainMessage() string {
 	if len(agg) == 0 {
 		// This should never happen, really.
 		return ""
 	}
 	if len(agg) == 1 {
-		return agg[0].Error()
+		return plainMessage(agg[0])
 	}
 	seenerrs := sets.NewString()
 	result := ""
 	agg.visit(func(err error) bool {
-		msg := err.Error()
+		msg := plainMessage(err)
 		if seenerrs.Has(msg) {
 			return false
 		}
@@ -112,6 +117,115 @@ func (agg aggregate) Errors() []error {
 	return []error(agg)
 }
 
+// AggregateCodes returns the distinct codes of the registered Coders found in
+// the errors (or nested errors) of agg, in the order they first appear.
+func AggregateCodes(agg Aggregate) []int {
+	var codes []int
+	seen := make(map[int]struct{})
+	for _, coder := range aggregateCoders(agg, nil) {
+		if _, ok := seen[coder.Code()]; ok {
+			continue
+		}
+		seen[coder.Code()] = struct{}{}
+		codes = append(codes, coder.Code())
+	}
+	return codes
+}
+
+// CoderPolicy selects the Coder that represents an Aggregate from the
+// registered Coders of its errors. The coders slice is never empty and is
+// ordered as the errors appear in the Aggregate.
+type CoderPolicy func(coders []Coder) Coder
+
+var aggregatePolicy CoderPolicy = HighestStatusPolicy
+
+// SetCoderPolicy sets the CoderPolicy used by ParseCoder to derive the Coder
+// of an Aggregate. The default policy is HighestStatusPolicy.
+func SetCoderPolicy(policy CoderPolicy) {
+	if policy == nil {
+		policy = HighestStatusPolicy
+	}
+	mux.Lock()
+	defer mux.Unlock()
+
+	aggregatePolicy = policy
+}
+
+// FirstCoderPolicy selects the Coder of the first error.
+func FirstCoderPolicy(coders []Coder) Coder {
+	return coders[0]
+}
+
+// HighestStatusPolicy selects the Coder with the highest HTTP status. Ties
+// are resolved in favour of the first error.
+func HighestStatusPolicy(coders []Coder) Coder {
+	selected := coders[0]
+	for _, coder := range coders[1:] {
+		if coder.HTTPStatus() > selected.HTTPStatus() {
+			selected = coder
+		}
+	}
+	return selected
+}
+
+// MostFrequentPolicy selects the Coder whose code occurs most often. Ties
+// are resolved in favour of the code that appears first.
+func MostFrequentPolicy(coders []Coder) Coder {
+	counts := make(map[int]int, len(coders))
+	for _, coder := range coders {
+		counts[coder.Code()]++
+	}
+	selected := coders[0]
+	for _, coder := range coders[1:] {
+		if counts[coder.Code()] > counts[selected.Code()] {
+			selected = coder
+		}
+	}
+	return selected
+}
+
+// RankPolicy returns a CoderPolicy that selects the Coder ranked first by
+// less. Ties are resolved in favour of the first error.
+func RankPolicy(less func(a, b Coder) bool) CoderPolicy {
+	return func(coders []Coder) Coder {
+		selected := coders[0]
+		for _, coder := range coders[1:] {
+			if less(coder, selected) {
+				selected = coder
+			}
+		}
+		return selected
+	}
+}
+
+// aggregateCoder returns the Coder selected by the CoderPolicy among the
+// Coders of agg, or false if none of its errors has a registered code. The
+// codes found that are not registered are passed to unknown, see parseCoder.
+func aggregateCoder(agg Aggregate, unknown func(code int)) (Coder, bool) {
+	coders := aggregateCoders(agg, unknown)
+	if len(coders) == 0 {
+		return nil, false
+	}
+	mux.RLock()
+	policy := aggregatePolicy
+	mux.RUnlock()
+
+	return policy(coders), true
+}
+
+// aggregateCoders returns the registered Coders of the errors (or nested
+// errors) in agg, skipping the errors that are parsed as the fallback Coder.
+func aggregateCoders(agg Aggregate, unknown func(code int)) []Coder {
+	var coders []Coder
+	aggregate(agg.Errors()).visit(func(err error) bool {
+		if coder, ok := parseCoder(err, unknown); ok {
+			coders = append(coders, coder)
+		}
+		return false
+	})
+	return coders
+}
+
 // Matcher is used to match errors.  Returns true if the error matches.
 type Matcher func(error) bool
 
@@ -160,6 +274,97 @@ func filterErrors(list []error, fns ...Matcher) []error {
 	return result
 }
 
+// Partition splits err into the errors that match fn and the rest.  If the
+// input is a singular error, only that error is tested.  If the input
+// implements the Aggregate interface, the list of errors will be processed
+// recursively and both results are Aggregates.  A result is nil if no error
+// belongs to it.
+//
+// This can be used, for example, to split the errors of a bulk operation into
+// retryable and fatal ones.
+func Partition(err error, fn Matcher) (matched, rest error) {
+	if err == nil {
+		return nil, nil
+	}
+	if agg, ok := err.(Aggregate); ok {
+		matchedList, restList := partitionErrors(agg.Errors(), fn)
+		return NewAggregate(matchedList), NewAggregate(restList)
+	}
+	if fn(err) {
+		return err, nil
+	}
+	return nil, err
+}
+
+// partitionErrors returns the errors (or nested errors, if the list contains
+// nested Errors) for which fn returns true, and the ones for which it
+// returns false.
+func partitionErrors(list []error, fn Matcher) (matched, rest []error) {
+	for _, err := range list {
+		m, r := Partition(err, fn)
+		if m != nil {
+			matched = append(matched, m)
+		}
+		if r != nil {
+			rest = append(rest, r)
+		}
+	}
+	return matched, rest
+}
+
+// GroupBy groups the errors of err by the key returned by fn.  If the input
+// is a singular error, the result holds a single group.  If the input
+// implements the Aggregate interface, the list of errors will be processed
+// recursively and nested Aggregates are flattened into the groups.
+func GroupBy(err error, fn func(error) string) map[string]Aggregate {
+	if err == nil {
+		return nil
+	}
+	groups := make(map[string][]error)
+	eachError(err, func(err error) {
+		key := fn(err)
+		groups[key] = append(groups[key], err)
+	})
+	result := make(map[string]Aggregate, len(groups))
+	for key, list := range groups {
+		result[key] = NewAggregate(list)
+	}
+	return result
+}
+
+// GroupByCode groups the errors of err by the code of their Coder, as
+// returned by ParseCoder.  Errors without a registered code are grouped
+// under the Unknown Code.  See GroupBy for how err is processed.
+func GroupByCode(err error) map[int]Aggregate {
+	if err == nil {
+		return nil
+	}
+	groups := make(map[int][]error)
+	eachError(err, func(err error) {
+		code := coderOf(err).Code()
+		groups[code] = append(groups[code], err)
+	})
+	result := make(map[int]Aggregate, len(groups))
+	for code, list := range groups {
+		result[code] = NewAggregate(list)
+	}
+	return result
+}
+
+// eachError calls f for err, or for each error (or nested error) if err
+// implements the Aggregate interface.
+func eachError(err error, f func(error)) {
+	if agg, ok := err.(Aggregate); ok {
+		for _, e := range agg.Errors() {
+			if e != nil {
+				eachError(e, f)
+			}
+		}
+		return
+	}
+	f(err)
+}
+
 // Flatten takes an Aggregate, which may hold other Aggregates in arbitrary
 // nesting, and flattens them all into a single Aggregate, recursively.
 func Flatten(agg Aggregate) Aggregate {
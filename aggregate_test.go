@@ -3,6 +3,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"sort"
 	"testing"
@@ -581,3 +582,205 @@ func ExampleNewAggregate() {
 	// Output:
 	// [error 1, error 2, error 3]
 }
+
+func TestAggregateCoder(t *testing.T) {
+	codes := []Coder{
+		defaultCoder{code: 20001, status: 400, msg: "bad request"},
+		defaultCoder{code: 20002, status: 404, msg: "not found"},
+		defaultCoder{code: 20003, status: 503, msg: "unavailable"},
+	}
+	for _, v := range codes {
+		Register(v)
+	}
+	defer func() {
+		for _, v := range codes {
+			Unregister(v)
+		}
+	}()
+
+	agg := NewAggregate([]error{
+		WithCode(errors.New("a"), 20002),
+		errors.New("no code"),
+		WithCode(errors.New("b"), 20001),
+		NewAggregate([]error{WithCode(errors.New("c"), 20003), WithCode(errors.New("d"), 20001)}),
+		WithCode(errors.New("e"), 99999),
+	})
+
+	testCases := []struct {
+		name   string
+		policy CoderPolicy
+		want   int
+	}{
+		{"default", nil, 20003},
+		{"first", FirstCoderPolicy, 20002},
+		{"highest status", HighestStatusPolicy, 20003},
+		{"most frequent", MostFrequentPolicy, 20001},
+		{"rank", RankPolicy(func(a, b Coder) bool { return a.Code() < b.Code() }), 20001},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetCoderPolicy(tc.policy)
+			defer SetCoderPolicy(nil)
+
+			if got := ParseCoder(agg).Code(); got != tc.want {
+				t.Errorf("ParseCoder: want: %d, got: %d", tc.want, got)
+			}
+			if got := ParseCoder(Wrap(agg, "batch")).Code(); got != tc.want {
+				t.Errorf("ParseCoder wrapped: want: %d, got: %d", tc.want, got)
+			}
+		})
+	}
+
+	if got := ParseCoder(NewAggregate([]error{errors.New("a"), WithCode(errors.New("b"), 99999)})); got != unknownCode {
+		t.Errorf("ParseCoder: want: unknown, got: %v", got)
+	}
+	if got := ParseCoder(WithCode(agg, 20002)).Code(); got != 20002 {
+		t.Errorf("ParseCoder: want: %d, got: %d", 20002, got)
+	}
+}
+
+func TestAggregateIsCode(t *testing.T) {
+	agg := NewAggregate([]error{
+		errors.New("a"),
+		WithCode(errors.New("b"), 20001),
+		NewAggregate([]error{WithMessage(WithCode(errors.New("c"), 20002), "nested")}),
+	})
+	runs := []struct {
+		expected bool
+		code     int
+		err      error
+	}{
+		{true, 20001, agg},
+		{true, 20002, agg},
+		{false, 20003, agg},
+		{true, 20002, Wrap(agg, "wrapped")},
+		{true, 20003, WithCode(agg, 20003)},
+	}
+	for _, r := range runs {
+		if got := IsCode(r.err, r.code); got != r.expected {
+			t.Errorf("IsCode(%d): want: %v, got: %v", r.code, r.expected, got)
+		}
+	}
+}
+
+func TestAggregateCodes(t *testing.T) {
+	codes := []Coder{
+		defaultCoder{code: 20001, status: 400, msg: "bad request"},
+		defaultCoder{code: 20002, status: 404, msg: "not found"},
+	}
+	for _, v := range codes {
+		Register(v)
+	}
+	defer func() {
+		for _, v := range codes {
+			Unregister(v)
+		}
+	}()
+
+	agg := NewAggregate([]error{
+		WithCode(errors.New("a"), 20002),
+		errors.New("no code"),
+		WithCode(errors.New("b"), 20001),
+		NewAggregate([]error{WithCode(errors.New("c"), 20002), WithCode(errors.New("d"), 99999)}),
+	})
+	if got, want := AggregateCodes(agg), []int{20002, 20001}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AggregateCodes: want: %v, got: %v", want, got)
+	}
+	if got := AggregateCodes(NewAggregate([]error{errors.New("a")})); len(got) != 0 {
+		t.Errorf("AggregateCodes: want: empty, got: %v", got)
+	}
+}
+
+func TestPartition(t *testing.T) {
+	isEOF := func(err error) bool { return errors.Is(err, io.EOF) }
+	testCases := []struct {
+		err     error
+		matched error
+		rest    error
+	}{
+		{
+			nil,
+			nil,
+			nil,
+		},
+		{
+			io.EOF,
+			io.EOF,
+			nil,
+		},
+		{
+			fmt.Errorf("abc"),
+			nil,
+			fmt.Errorf("abc"),
+		},
+		{
+			aggregate{fmt.Errorf("abc"), io.EOF},
+			aggregate{io.EOF},
+			aggregate{fmt.Errorf("abc")},
+		},
+		{
+			aggregate{io.EOF, io.EOF},
+			aggregate{io.EOF, io.EOF},
+			nil,
+		},
+		{
+			aggregate{fmt.Errorf("abc"), aggregate{io.EOF, fmt.Errorf("def")}},
+			aggregate{aggregate{io.EOF}},
+			aggregate{fmt.Errorf("abc"), aggregate{fmt.Errorf("def")}},
+		},
+	}
+	for i, tc := range testCases {
+		matched, rest := Partition(tc.err, isEOF)
+		if !reflect.DeepEqual(tc.matched, matched) {
+			t.Errorf("%d: matched: expected %#v, got %#v", i, tc.matched, matched)
+		}
+		if !reflect.DeepEqual(tc.rest, rest) {
+			t.Errorf("%d: rest: expected %#v, got %#v", i, tc.rest, rest)
+		}
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	if got := GroupBy(nil, func(error) string { return "" }); got != nil {
+		t.Errorf("expected nil, got %#v", got)
+	}
+
+	err := aggregate{
+		fmt.Errorf("a1"),
+		fmt.Errorf("b1"),
+		aggregate{fmt.Errorf("a2"), aggregate{fmt.Errorf("c1")}},
+	}
+	got := GroupBy(err, func(err error) string { return err.Error()[:1] })
+	expected := map[string]Aggregate{
+		"a": aggregate{fmt.Errorf("a1"), fmt.Errorf("a2")},
+		"b": aggregate{fmt.Errorf("b1")},
+		"c": aggregate{fmt.Errorf("c1")},
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	got = GroupBy(fmt.Errorf("a1"), func(err error) string { return "single" })
+	if len(got) != 1 || len(got["single"].Errors()) != 1 {
+		t.Errorf("expected a single group, got %v", got)
+	}
+}
+
+func TestGroupByCode(t *testing.T) {
+	mockCode := defaultCoder{code: 20001, status: 400, msg: "bad request"}
+	Register(mockCode)
+	defer Unregister(mockCode)
+
+	e1 := WithCode(errors.New("a"), 20001)
+	e2 := errors.New("b")
+	e3 := WithCode(errors.New("c"), 20001)
+	e4 := WithCode(errors.New("d"), 99999)
+	got := GroupByCode(aggregate{e1, e2, aggregate{e3, e4}})
+	expected := map[int]Aggregate{
+		20001: aggregate{e1, e3},
+		1:     aggregate{e2, e4},
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,741 @@
+// Package analysis defines an Analyzer that reports common misuses of
+// github.com/shipengqi/errors.
+//
+// The Analyzer runs the following checks, each of which can be disabled with
+// the flag of the same name:
+//
+//	doublewrap:       a stack trace is recorded for an error that has one
+//	                  already, as in errors.Wrap(errors.New("x"), "y")
+//	unregisteredcode: a constant code given to WithCode and the like is not
+//	                  registered with Register and the like by the package or
+//	                  its imports
+//	discarded:        the result of a constructor or wrapper is not used
+//	nilwrap:          an error known to be nil is wrapped, which returns nil
+//	errorfw:          a format string given to Errorf and the like uses %w,
+//	                  which only fmt.Errorf supports
+//	templateargs:     the format given to DefineTemplate has not one verb per
+//	                  parameter, or the New method of a Template defined by
+//	                  the package is not given one argument per parameter
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// errorsPath is the import path of the analyzed package.
+const errorsPath = "github.com/shipengqi/errors"
+
+// Analyzer reports common misuses of github.com/shipengqi/errors.
+var Analyzer = &analysis.Analyzer{
+	Name:      "errorsvet",
+	Doc:       "report common misuses of github.com/shipengqi/errors",
+	URL:       "https://pkg.go.dev/github.com/shipengqi/errors/analysis",
+	Requires:  []*analysis.Analyzer{inspect.Analyzer},
+	FactTypes: []analysis.Fact{new(registeredCodes)},
+	Run:       run,
+}
+
+var (
+	checkDoubleWrap       bool
+	checkUnregisteredCode bool
+	checkDiscarded        bool
+	checkNilWrap          bool
+	checkErrorfW          bool
+	checkTemplateArgs     bool
+)
+
+func init() {
+	Analyzer.Flags.BoolVar(&checkDoubleWrap, "doublewrap", true, "report a stack trace recorded twice")
+	Analyzer.Flags.BoolVar(&checkUnregisteredCode, "unregisteredcode", true, "report constant codes that are not registered")
+	Analyzer.Flags.BoolVar(&checkDiscarded, "discarded", true, "report unused results of constructors and wrappers")
+	Analyzer.Flags.BoolVar(&checkNilWrap, "nilwrap", true, "report wraps of errors known to be nil")
+	Analyzer.Flags.BoolVar(&checkErrorfW, "errorfw", true, "report %w in the format strings of Errorf and the like")
+	Analyzer.Flags.BoolVar(&checkTemplateArgs, "templateargs", true, "report templates and their instances not matching their parameters")
+}
+
+var (
+	// stackFuncs record a stack trace; the value is the index of the
+	// wrapped error, or -1 if there is none.
+	stackFuncs = map[string]int{
+		"New":       -1,
+		"Errorf":    -1,
+		"NewCtx":    -1,
+		"WithStack": 0,
+		"Wrap":      0,
+		"Wrapf":     0,
+		"WrapCode":  0,
+		"WrapCodef": 0,
+		"WrapCtx":   1,
+	}
+
+	// wrapFuncs return nil when the wrapped error is nil; the value is the
+	// index of the wrapped error.
+	wrapFuncs = map[string]int{
+		"WithStack":         0,
+		"Wrap":              0,
+		"Wrapf":             0,
+		"WithMessage":       0,
+		"WithMessagef":      0,
+		"WrapCode":          0,
+		"WrapCodef":         0,
+		"WithCode":          0,
+		"WithCodef":         0,
+		"WrapCtx":           1,
+		"WithFields":        0,
+		"WithSeverity":      0,
+		"WithRetryable":     0,
+		"WithPublicMessage": 0,
+	}
+
+	// codeFuncs take a code; the value is the index of the code.
+	codeFuncs = map[string]int{
+		"WithCode":  1,
+		"WithCodef": 1,
+		"WrapCode":  1,
+		"WrapCodef": 1,
+	}
+
+	// formatFuncs take a format string; the value is the index of the format.
+	formatFuncs = map[string]int{
+		"Errorf":       0,
+		"Wrapf":        1,
+		"WithMessagef": 1,
+		"WrapCodef":    2,
+		"WithCodef":    2,
+	}
+)
+
+// unknownCode is the code reserved by the package for unknown errors.
+const unknownCode = 1
+
+// registeredCodes is the package fact listing the codes registered by a
+// package with Register, TryRegister, RegisterAll, Define or
+// DefineTemplate.
+type registeredCodes struct {
+	Codes []int
+}
+
+func (*registeredCodes) AFact() {}
+
+func (f *registeredCodes) String() string {
+	return fmt.Sprintf("registeredCodes(%v)", f.Codes)
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	c := &checker{pass: pass, ins: ins}
+	c.registered = c.collectCodes()
+
+	if checkDoubleWrap {
+		ins.Preorder([]ast.Node{(*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)}, func(n ast.Node) {
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				if n.Body != nil {
+					c.doubleWrap(n.Body)
+				}
+			case *ast.FuncLit:
+				c.doubleWrap(n.Body)
+			}
+		})
+	}
+	if checkNilWrap {
+		ins.Preorder([]ast.Node{
+			(*ast.BlockStmt)(nil), (*ast.CaseClause)(nil), (*ast.CommClause)(nil),
+		}, func(n ast.Node) {
+			switch n := n.(type) {
+			case *ast.BlockStmt:
+				c.nilWrap(n.List)
+			case *ast.CaseClause:
+				c.nilWrap(n.Body)
+			case *ast.CommClause:
+				c.nilWrap(n.Body)
+			}
+		})
+	}
+	var defs map[types.Object]ast.Expr
+	if checkTemplateArgs {
+		defs = c.definitions()
+	}
+	ins.Preorder([]ast.Node{(*ast.ExprStmt)(nil), (*ast.CallExpr)(nil)}, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.ExprStmt:
+			if checkDiscarded {
+				c.discarded(n)
+			}
+		case *ast.CallExpr:
+			if checkUnregisteredCode {
+				c.unregisteredCode(n)
+			}
+			if checkErrorfW {
+				c.errorfW(n)
+			}
+			if checkTemplateArgs {
+				c.templateArgs(n, defs)
+			}
+		}
+	})
+	return nil, nil
+}
+
+type checker struct {
+	pass       *analysis.Pass
+	ins        *inspector.Inspector
+	registered map[int]bool
+}
+
+// errorsFunc returns the name of the function of the errors package called
+// by call, or an empty string.
+func (c *checker) errorsFunc(call *ast.CallExpr) string {
+	fn, ok := typeutil.Callee(c.pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != errorsPath {
+		return ""
+	}
+	if sig, ok := fn.Type().(*types.Signature); !ok || sig.Recv() != nil {
+		return ""
+	}
+	return fn.Name()
+}
+
+// doubleWrap reports the calls of body recording a stack trace for an error
+// that has one already, either because it is the result of a call recording
+// one or because it is a variable last assigned such a result. The
+// assignments are followed in source order, regardless of the control flow.
+func (c *checker) doubleWrap(body *ast.BlockStmt) {
+	captured := make(map[types.Object]string)
+	assign := func(lhs, rhs ast.Expr) {
+		id, ok := ast.Unparen(lhs).(*ast.Ident)
+		if !ok {
+			return
+		}
+		obj := c.pass.TypesInfo.ObjectOf(id)
+		if obj == nil {
+			return
+		}
+		if call, ok := ast.Unparen(rhs).(*ast.CallExpr); ok {
+			if name := c.errorsFunc(call); name != "" {
+				if _, ok := stackFuncs[name]; ok {
+					captured[obj] = name
+					return
+				}
+			}
+		}
+		delete(captured, obj)
+	}
+
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.AssignStmt:
+			for _, rhs := range n.Rhs {
+				ast.Inspect(rhs, visit)
+			}
+			for i, lhs := range n.Lhs {
+				var rhs ast.Expr
+				if len(n.Lhs) == len(n.Rhs) {
+					rhs = n.Rhs[i]
+				}
+				assign(lhs, rhs)
+			}
+			return false
+		case *ast.ValueSpec:
+			for _, v := range n.Values {
+				ast.Inspect(v, visit)
+			}
+			for i, name := range n.Names {
+				var rhs ast.Expr
+				if len(n.Names) == len(n.Values) {
+					rhs = n.Values[i]
+				}
+				assign(name, rhs)
+			}
+			return false
+		case *ast.CallExpr:
+			name := c.errorsFunc(n)
+			i, ok := stackFuncs[name]
+			if !ok || i < 0 || i >= len(n.Args) {
+				return true
+			}
+			arg := ast.Unparen(n.Args[i])
+			var by string
+			switch arg := arg.(type) {
+			case *ast.CallExpr:
+				if inner := c.errorsFunc(arg); inner != "" {
+					if _, ok := stackFuncs[inner]; ok {
+						by = inner
+					}
+				}
+			case *ast.Ident:
+				by = captured[c.pass.TypesInfo.ObjectOf(arg)]
+			}
+			if by != "" {
+				c.pass.Reportf(n.Pos(), "%s records a second stack trace for an error recorded by %s; use WithMessage instead", name, by)
+			}
+		}
+		return true
+	}
+	ast.Inspect(body, visit)
+}
+
+// discarded reports the calls of constructors and wrappers whose result is
+// not used.
+func (c *checker) discarded(stmt *ast.ExprStmt) {
+	call, ok := ast.Unparen(stmt.X).(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	name := c.errorsFunc(call)
+	_, stack := stackFuncs[name]
+	_, wrap := wrapFuncs[name]
+	if stack || wrap || name == "NewAggregate" {
+		c.pass.Reportf(call.Pos(), "result of errors.%s is not used", name)
+	}
+}
+
+// nilWrap reports the wraps of errors known to be nil in the statements of a
+// block: in the body of an "if err == nil" statement, and after an
+// "if err != nil" statement whose body does not fall through.
+func (c *checker) nilWrap(stmts []ast.Stmt) {
+	for i, stmt := range stmts {
+		ifStmt, ok := stmt.(*ast.IfStmt)
+		if !ok {
+			continue
+		}
+		obj, op := c.nilCheck(ifStmt.Cond)
+		switch {
+		case obj == nil:
+		case op == token.EQL:
+			c.nilUses(ifStmt.Body.List, obj)
+		case op == token.NEQ && ifStmt.Else == nil && terminates(ifStmt.Body):
+			c.nilUses(stmts[i+1:], obj)
+		}
+	}
+}
+
+// nilCheck returns the error variable compared to nil by cond, and the
+// comparison operator.
+func (c *checker) nilCheck(cond ast.Expr) (types.Object, token.Token) {
+	bin, ok := ast.Unparen(cond).(*ast.BinaryExpr)
+	if !ok || (bin.Op != token.EQL && bin.Op != token.NEQ) {
+		return nil, 0
+	}
+	x, y := ast.Unparen(bin.X), ast.Unparen(bin.Y)
+	if c.isNil(x) {
+		x, y = y, x
+	}
+	id, ok := x.(*ast.Ident)
+	if !ok || !c.isNil(y) {
+		return nil, 0
+	}
+	obj, ok := c.pass.TypesInfo.ObjectOf(id).(*types.Var)
+	if !ok || !types.Identical(obj.Type(), types.Universe.Lookup("error").Type()) {
+		return nil, 0
+	}
+	return obj, bin.Op
+}
+
+func (c *checker) isNil(e ast.Expr) bool {
+	tv, ok := c.pass.TypesInfo.Types[e]
+	return ok && tv.IsNil()
+}
+
+// nilUses reports the wraps of obj in stmts, up to its next assignment.
+func (c *checker) nilUses(stmts []ast.Stmt, obj types.Object) {
+	for _, stmt := range stmts {
+		if as, ok := stmt.(*ast.AssignStmt); ok && c.assigns(as, obj) {
+			for _, rhs := range as.Rhs {
+				c.reportNilWraps(rhs, obj)
+			}
+			return
+		}
+		if c.assignsWithin(stmt, obj) {
+			return
+		}
+		c.reportNilWraps(stmt, obj)
+	}
+}
+
+func (c *checker) reportNilWraps(n ast.Node, obj types.Object) {
+	ast.Inspect(n, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		name := c.errorsFunc(call)
+		i, ok := wrapFuncs[name]
+		if !ok || i >= len(call.Args) {
+			return true
+		}
+		if id, ok := ast.Unparen(call.Args[i]).(*ast.Ident); ok && c.pass.TypesInfo.ObjectOf(id) == obj {
+			c.pass.Reportf(call.Pos(), "%s is nil here, so errors.%s returns nil", id.Name, name)
+		}
+		return true
+	})
+}
+
+func (c *checker) assigns(as *ast.AssignStmt, obj types.Object) bool {
+	for _, lhs := range as.Lhs {
+		if id, ok := ast.Unparen(lhs).(*ast.Ident); ok && c.pass.TypesInfo.ObjectOf(id) == obj {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *checker) assignsWithin(n ast.Node, obj types.Object) bool {
+	found := false
+	ast.Inspect(n, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			found = found || c.assigns(n, obj)
+		case *ast.UnaryExpr:
+			// &err may be used to assign it
+			if id, ok := ast.Unparen(n.X).(*ast.Ident); ok && n.Op == token.AND {
+				found = found || c.pass.TypesInfo.ObjectOf(id) == obj
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// terminates reports whether the last statement of block leaves it.
+func terminates(block *ast.BlockStmt) bool {
+	if len(block.List) == 0 {
+		return false
+	}
+	switch s := block.List[len(block.List)-1].(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.BranchStmt:
+		return s.Tok == token.BREAK || s.Tok == token.CONTINUE || s.Tok == token.GOTO
+	case *ast.ExprStmt:
+		if call, ok := s.X.(*ast.CallExpr); ok {
+			if id, ok := call.Fun.(*ast.Ident); ok && id.Name == "panic" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// errorfW reports the format strings using %w given to the functions of the
+// errors package, which format them with fmt.Sprintf.
+func (c *checker) errorfW(call *ast.CallExpr) {
+	name := c.errorsFunc(call)
+	i, ok := formatFuncs[name]
+	if !ok || i >= len(call.Args) {
+		return
+	}
+	tv := c.pass.TypesInfo.Types[call.Args[i]]
+	if tv.Value == nil || tv.Value.Kind() != constant.String {
+		return
+	}
+	if hasVerbW(constant.StringVal(tv.Value)) {
+		c.pass.Reportf(call.Args[i].Pos(), "errors.%s does not support %%w; use Wrapf to wrap an error", name)
+	}
+}
+
+// hasVerbW reports whether format has a %w verb.
+func hasVerbW(format string) bool {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		// skip the flags, width and precision
+		j := i + 1
+		for j < len(format) && strings.IndexByte("+-# 0123456789.*[]", format[j]) >= 0 {
+			j++
+		}
+		if j < len(format) && format[j] == 'w' {
+			return true
+		}
+		i = j
+	}
+	return false
+}
+
+// templateArgs reports the DefineTemplate calls whose constant format has not
+// one verb per parameter, which panic, and the calls of the New method of a
+// Template defined by the package that are not given one argument per
+// parameter.
+func (c *checker) templateArgs(call *ast.CallExpr, defs map[types.Object]ast.Expr) {
+	if c.errorsFunc(call) == "DefineTemplate" {
+		params, ok := templateParams(call)
+		if !ok {
+			return
+		}
+		tv := c.pass.TypesInfo.Types[call.Args[2]]
+		if tv.Value == nil || tv.Value.Kind() != constant.String {
+			return
+		}
+		if n := countVerbs(constant.StringVal(tv.Value)); n != params {
+			c.pass.Reportf(call.Args[2].Pos(), "DefineTemplate format has %d verbs but %d parameters", n, params)
+		}
+		return
+	}
+
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "New" || call.Ellipsis.IsValid() {
+		return
+	}
+	fn, ok := c.pass.TypesInfo.ObjectOf(sel.Sel).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != errorsPath {
+		return
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return
+	}
+	if ptr, ok := recv.Type().(*types.Pointer); !ok || types.TypeString(ptr.Elem(), nil) != errorsPath+".Template" {
+		return
+	}
+	id, ok := ast.Unparen(sel.X).(*ast.Ident)
+	if !ok {
+		return
+	}
+	def, ok := defs[c.pass.TypesInfo.ObjectOf(id)].(*ast.CallExpr)
+	if !ok || c.errorsFunc(def) != "DefineTemplate" {
+		return
+	}
+	if params, ok := templateParams(def); ok && params != len(call.Args) {
+		c.pass.Reportf(call.Lparen, "%s.New takes %d arguments, got %d", id.Name, params, len(call.Args))
+	}
+}
+
+// templateParams returns the number of parameters given to a DefineTemplate
+// call as a composite literal.
+func templateParams(call *ast.CallExpr) (int, bool) {
+	if len(call.Args) < 4 {
+		return 0, false
+	}
+	lit, ok := ast.Unparen(call.Args[3]).(*ast.CompositeLit)
+	if !ok {
+		return 0, false
+	}
+	return len(lit.Elts), true
+}
+
+// countVerbs returns the number of verbs of format, not counting %%.
+func countVerbs(format string) int {
+	n := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		// skip the flags, width and precision
+		j := i + 1
+		for j < len(format) && strings.IndexByte("+-# 0123456789.*[]", format[j]) >= 0 {
+			j++
+		}
+		if j < len(format) && format[j] != '%' {
+			n++
+		}
+		i = j
+	}
+	return n
+}
+
+// unregisteredCode reports the constant codes that are neither registered by
+// the package nor by its dependencies.
+func (c *checker) unregisteredCode(call *ast.CallExpr) {
+	name := c.errorsFunc(call)
+	i, ok := codeFuncs[name]
+	if !ok || i >= len(call.Args) {
+		return
+	}
+	code, ok := c.constInt(call.Args[i])
+	if !ok || code == unknownCode || c.registered[code] {
+		return
+	}
+	c.pass.Reportf(call.Args[i].Pos(), "code %d is not registered", code)
+}
+
+func (c *checker) constInt(e ast.Expr) (int, bool) {
+	tv, ok := c.pass.TypesInfo.Types[e]
+	if !ok || tv.Value == nil {
+		return 0, false
+	}
+	v, ok := constant.Int64Val(constant.ToInt(tv.Value))
+	return int(v), ok
+}
+
+// collectCodes exports the codes registered by the package as a fact, and
+// returns them together with the codes registered by its dependencies.
+func (c *checker) collectCodes() map[int]bool {
+	codes := make(map[int]bool)
+	for _, f := range c.pass.AllPackageFacts() {
+		if rc, ok := f.Fact.(*registeredCodes); ok {
+			for _, code := range rc.Codes {
+				codes[code] = true
+			}
+		}
+	}
+
+	defs := c.definitions()
+	var own []int
+	c.ins.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		if len(call.Args) == 0 {
+			return
+		}
+		var coders []ast.Expr
+		switch c.errorsFunc(call) {
+		case "Register", "TryRegister":
+			coders = call.Args[:1]
+		case "RegisterAll":
+			if lit, ok := ast.Unparen(call.Args[0]).(*ast.CompositeLit); ok {
+				coders = lit.Elts
+			}
+		case "Define":
+			if code, ok := c.constInt(call.Args[0]); ok {
+				own = append(own, code)
+			}
+		case "DefineTemplate":
+			if len(call.Args) < 2 {
+				return
+			}
+			if code, ok := c.constInt(call.Args[1]); ok {
+				own = append(own, code)
+			}
+		}
+		for _, e := range coders {
+			if code, ok := c.coderCode(e, defs); ok {
+				own = append(own, code)
+			}
+		}
+	})
+	if len(own) > 0 {
+		sort.Ints(own)
+		c.pass.ExportPackageFact(&registeredCodes{Codes: own})
+	}
+	for _, code := range own {
+		codes[code] = true
+	}
+	return codes
+}
+
+// definitions returns the expressions last assigned to the variables of the
+// package, in source order.
+func (c *checker) definitions() map[types.Object]ast.Expr {
+	defs := make(map[types.Object]ast.Expr)
+	define := func(lhs []ast.Expr, rhs []ast.Expr) {
+		if len(lhs) != len(rhs) {
+			return
+		}
+		for i, l := range lhs {
+			if id, ok := l.(*ast.Ident); ok {
+				if obj := c.pass.TypesInfo.ObjectOf(id); obj != nil {
+					defs[obj] = rhs[i]
+				}
+			}
+		}
+	}
+	c.ins.Preorder([]ast.Node{(*ast.AssignStmt)(nil), (*ast.ValueSpec)(nil)}, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			define(n.Lhs, n.Rhs)
+		case *ast.ValueSpec:
+			names := make([]ast.Expr, len(n.Names))
+			for i, name := range n.Names {
+				names[i] = name
+			}
+			define(names, n.Values)
+		}
+	})
+	return defs
+}
+
+// coderCode returns the code of the Coder e, when its Code method returns a
+// constant, or a field of its receiver set by a composite literal.
+func (c *checker) coderCode(e ast.Expr, defs map[types.Object]ast.Expr) (int, bool) {
+	for i := 0; i < 8; i++ {
+		e = ast.Unparen(e)
+		if u, ok := e.(*ast.UnaryExpr); ok && u.Op == token.AND {
+			e = u.X
+			continue
+		}
+		id, ok := e.(*ast.Ident)
+		if !ok {
+			break
+		}
+		def, ok := defs[c.pass.TypesInfo.ObjectOf(id)]
+		if !ok {
+			break
+		}
+		e = def
+	}
+
+	t := c.pass.TypesInfo.TypeOf(e)
+	if t == nil {
+		return 0, false
+	}
+	obj, _, _ := types.LookupFieldOrMethod(t, true, c.pass.Pkg, "Code")
+	method, ok := obj.(*types.Func)
+	if !ok {
+		return 0, false
+	}
+	decl := c.funcDecl(method)
+	if decl == nil || decl.Body == nil || len(decl.Body.List) != 1 {
+		return 0, false
+	}
+	ret, ok := decl.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return 0, false
+	}
+	result := ast.Unparen(ret.Results[0])
+	if code, ok := c.constInt(result); ok {
+		return code, true
+	}
+
+	// return d.code, with d the receiver
+	sel, ok := result.(*ast.SelectorExpr)
+	if !ok {
+		return 0, false
+	}
+	lit, ok := e.(*ast.CompositeLit)
+	if !ok {
+		return 0, false
+	}
+	st, ok := c.pass.TypesInfo.TypeOf(lit).Underlying().(*types.Struct)
+	if !ok {
+		return 0, false
+	}
+	for i, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok && key.Name == sel.Sel.Name {
+				return c.constInt(kv.Value)
+			}
+		} else if i < st.NumFields() && st.Field(i).Name() == sel.Sel.Name {
+			return c.constInt(elt)
+		}
+	}
+	return 0, false
+}
+
+// funcDecl returns the declaration of fn in the package, if any.
+func (c *checker) funcDecl(fn *types.Func) *ast.FuncDecl {
+	if fn.Pkg() != c.pass.Pkg {
+		return nil
+	}
+	for _, f := range c.pass.Files {
+		for _, d := range f.Decls {
+			if fd, ok := d.(*ast.FuncDecl); ok && c.pass.TypesInfo.Defs[fd.Name] == fn {
+				return fd
+			}
+		}
+	}
+	return nil
+}
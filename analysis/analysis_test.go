@@ -0,0 +1,55 @@
+package analysis
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "a")
+}
+
+func TestHasVerbW(t *testing.T) {
+	tests := []struct {
+		format string
+		want   bool
+	}{
+		{"", false},
+		{"%v", false},
+		{"%w", true},
+		{"read: %w", true},
+		{"%+w", true},
+		{"%-10w", true},
+		{"100%%w", false},
+		{"%[1]w", true},
+		{"%d%w", true},
+	}
+
+	for _, tt := range tests {
+		if got := hasVerbW(tt.format); got != tt.want {
+			t.Errorf("hasVerbW(%q): want %t, got %t", tt.format, tt.want, got)
+		}
+	}
+}
+
+func TestCountVerbs(t *testing.T) {
+	tests := []struct {
+		format string
+		want   int
+	}{
+		{"", 0},
+		{"user", 0},
+		{"user %s", 1},
+		{"user %-8s in tenant %03d", 2},
+		{"100%%", 0},
+		{"%d%%%s", 2},
+		{"trailing %", 0},
+	}
+
+	for _, tt := range tests {
+		if got := countVerbs(tt.format); got != tt.want {
+			t.Errorf("countVerbs(%q): want %d, got %d", tt.format, tt.want, got)
+		}
+	}
+}
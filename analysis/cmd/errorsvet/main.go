@@ -0,0 +1,18 @@
+// Command errorsvet reports common misuses of github.com/shipengqi/errors.
+//
+// Usage:
+//
+//	errorsvet [-flag] [package]
+//
+// It can also be run by go vet:
+//
+//	go vet -vettool=$(which errorsvet) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/shipengqi/errors/analysis"
+)
+
+func main() { singlechecker.Main(analysis.Analyzer) }
@@ -0,0 +1,108 @@
+package a // want package:`registeredCodes\(\[20404 20405\]\)`
+
+import (
+	"context"
+	"io"
+
+	"codes"
+
+	"github.com/shipengqi/errors"
+)
+
+func doubleWrap() error {
+	err := errors.New("boom")
+	if err != nil {
+		return errors.Wrap(err, "doing") // want `Wrap records a second stack trace for an error recorded by New; use WithMessage instead`
+	}
+	return errors.WithStack(errors.Errorf("x %d", 1)) // want `WithStack records a second stack trace for an error recorded by Errorf`
+}
+
+func doubleWrapReassigned(r io.Reader) error {
+	err := errors.New("boom")
+	_, err = r.Read(nil)
+	return errors.Wrap(err, "read")
+}
+
+func doubleWrapSelf(r io.Reader) error {
+	_, err := r.Read(nil)
+	err = errors.Wrap(err, "read")
+	return errors.WithMessage(err, "again")
+}
+
+func unregistered(err error) error {
+	_ = errors.WithCode(err, codes.ErrUserNotFound)
+	_ = errors.WithCode(err, 20002)
+	_ = errors.WrapCode(err, 20003)
+	_ = errors.WithCode(err, 20004)
+	_ = errors.WithCode(err, 20005)
+	_ = errors.WithCode(err, 20006)
+	_ = errors.WrapCode(err, 20008)
+	_ = errors.WithCode(err, 1)
+	_ = errors.WithCode(err, 30001)          // want `code 30001 is not registered`
+	return errors.WrapCodef(err, 30002, "x") // want `code 30002 is not registered`
+}
+
+func discarded(err error) {
+	errors.Wrap(err, "ignored") // want `result of errors.Wrap is not used`
+	errors.New("ignored")       // want `result of errors.New is not used`
+	errors.WithCode(err, 20001) // want `result of errors.WithCode is not used`
+	errors.Register(nil)
+}
+
+func nilWrap(r io.Reader) error {
+	_, err := r.Read(nil)
+	if err == nil {
+		return errors.Wrap(err, "read") // want `err is nil here, so errors.Wrap returns nil`
+	}
+	return err
+}
+
+func nilWrapAfterCheck(ctx context.Context, r io.Reader) error {
+	_, err := r.Read(nil)
+	if err != nil {
+		return err
+	}
+	if _, err := r.Read(nil); err != nil {
+		return errors.Wrap(err, "shadowed")
+	}
+	return errors.WrapCtx(ctx, err, "read") // want `err is nil here, so errors.WrapCtx returns nil`
+}
+
+func nilWrapReassigned(r io.Reader) error {
+	_, err := r.Read(nil)
+	if err != nil {
+		return err
+	}
+	_, err = r.Read(nil)
+	return errors.Wrap(err, "read")
+}
+
+func nilWrapFallthrough(r io.Reader) error {
+	_, err := r.Read(nil)
+	if err != nil {
+		println(err)
+	}
+	return errors.Wrap(err, "read")
+}
+
+func errorfW(err error) error {
+	_ = errors.Errorf("read: %w", err)              // want `errors.Errorf does not support %w; use Wrapf to wrap an error`
+	_ = errors.Wrapf(err, "read %s: %+w", "x", err) // want `errors.Wrapf does not support %w`
+	_ = errors.Errorf("100%% %v", err)
+	return errors.Wrapf(err, "read %s", "x")
+}
+
+var (
+	errUserNotFound = errors.DefineTemplate("user_not_found", 20404, "user %s not found in tenant %03d (100%%)",
+		[]string{"user", "tenant"})
+	errBadTemplate = errors.DefineTemplate("bad_template", 20405, "user %s not found", // want `DefineTemplate format has 1 verbs but 2 parameters`
+		[]string{"user", "tenant"})
+)
+
+func templateArgs(args []interface{}) error {
+	_ = errUserNotFound.New("bob", 7)
+	_ = errUserNotFound.New("bob")       // want `errUserNotFound.New takes 2 arguments, got 1`
+	_ = errUserNotFound.New("bob", 7, 3) // want `errUserNotFound.New takes 2 arguments, got 3`
+	_ = errUserNotFound.New(args...)
+	return codes.ErrUserLocked.New()
+}
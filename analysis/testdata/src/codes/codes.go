@@ -0,0 +1,35 @@
+package codes
+
+import "github.com/shipengqi/errors"
+
+const ErrUserNotFound = 20001
+
+type coder struct {
+	code int
+	msg  string
+}
+
+func (c coder) Code() int         { return c.code }
+func (c coder) String() string    { return c.msg }
+func (c coder) Reference() string { return "" }
+func (c coder) HTTPStatus() int   { return 404 }
+
+type conflict struct{}
+
+func (conflict) Code() int         { return 20002 }
+func (conflict) String() string    { return "Conflict" }
+func (conflict) Reference() string { return "" }
+func (conflict) HTTPStatus() int   { return 409 }
+
+var ErrUserExpired = errors.Define(20004, "User expired")
+
+var ErrUserLocked = errors.DefineTemplate("user_locked", 20005, "User %s locked", []string{"user"})
+
+func init() {
+	notFound := coder{code: ErrUserNotFound, msg: "User not found"}
+	errors.Register(notFound)
+	errors.Register(&conflict{})
+	errors.Register(coder{20003, "Gone"})
+	_ = errors.TryRegister(coder{20006, "Locked"})
+	_ = errors.RegisterAll([]errors.Coder{coder{20007, "Moved"}, coder{20008, "Expired"}})
+}
@@ -0,0 +1,53 @@
+// Package errors is a stub of github.com/shipengqi/errors.
+package errors
+
+import "context"
+
+type Coder interface {
+	HTTPStatus() int
+	String() string
+	Reference() string
+	Code() int
+}
+
+type Fields map[string]interface{}
+
+func Register(code Coder) {}
+
+func TryRegister(code Coder) error { return nil }
+
+func RegisterAll(codes []Coder) error { return nil }
+
+type Sentinel struct{}
+
+func (s *Sentinel) Error() string { return "" }
+
+func Define(code int, msg string) *Sentinel { return &Sentinel{} }
+
+type Template struct{}
+
+func DefineTemplate(id string, code int, format string, params []string) *Template {
+	return &Template{}
+}
+
+func (t *Template) New(args ...interface{}) error { return nil }
+
+func New(message string) error                                         { return nil }
+func Errorf(format string, args ...interface{}) error                  { return nil }
+func NewCtx(ctx context.Context, message string) error                 { return nil }
+func WithStack(err error) error                                        { return err }
+func Wrap(err error, message string) error                             { return err }
+func Wrapf(err error, format string, args ...interface{}) error        { return err }
+func WithMessage(err error, message string) error                      { return err }
+func WithMessagef(err error, format string, args ...interface{}) error { return err }
+func WrapCode(err error, code int) error                               { return err }
+func WrapCodef(err error, code int, format string, args ...interface{}) error {
+	return err
+}
+func WithCode(err error, code int) error { return err }
+func WithCodef(err error, code int, format string, args ...interface{}) error {
+	return err
+}
+func WrapCtx(ctx context.Context, err error, message string) error { return err }
+func WithFields(err error, fields Fields) error                    { return err }
+func NewAggregate(errlist []error) error                           { return nil }
@@ -0,0 +1,153 @@
+// Command errcodes prints the error codes registered by Go packages, as
+// Markdown, JSON or OpenAPI responses.
+//
+// Usage:
+//
+//	errcodes [flags] package ...
+//
+// The packages are given as import paths or patterns, as for go list. They
+// usually register their codes in init functions or package-level
+// variables, with Register, Define or DefineTemplate. errcodes generates a
+// main package importing them, which it runs with go run in the current
+// directory; the packages must therefore be importable from the module of
+// the current directory. The flags are:
+//
+//	-format  the output format: markdown, json or openapi (default markdown)
+//	-main    print the generated main package instead of running it
+//
+// The Unknown Code of github.com/shipengqi/errors is part of the output, as
+// it is the code of the errors without a registered one.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+var (
+	outputFormat = flag.String("format", "markdown", "output format: markdown, json or openapi")
+	mainMode     = flag.Bool("main", false, "print the generated main package instead of running it")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: errcodes [flags] package ...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Args(), *outputFormat, *mainMode, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
+
+// exporters maps the output formats to the exporting functions of
+// github.com/shipengqi/errors.
+var exporters = map[string]string{
+	"markdown": "ExportMarkdown",
+	"json":     "ExportJSON",
+	"openapi":  "ExportOpenAPI",
+}
+
+var mainTemplate = template.Must(template.New("main").Parse(`// Code generated by errcodes. DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/shipengqi/errors"
+{{range .Packages}}
+	_ {{printf "%q" .}}{{end}}
+)
+
+func main() {
+	if err := errors.{{.Exporter}}(os.Stdout, errors.Codes()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`))
+
+// generate returns the source of the main package printing the codes
+// registered by the packages pkgs in the given format.
+func generate(pkgs []string, outputFormat string) ([]byte, error) {
+	exporter, ok := exporters[outputFormat]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q", outputFormat)
+	}
+	var buf bytes.Buffer
+	err := mainTemplate.Execute(&buf, struct {
+		Packages []string
+		Exporter string
+	}{pkgs, exporter})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// run prints the codes registered by the packages matching patterns, or the
+// main package printing them, to stdout. The errors of the go command are
+// written to stderr.
+func run(patterns []string, outputFormat string, printMain bool, stdout, stderr io.Writer) error {
+	pkgs, err := list(patterns, stderr)
+	if err != nil {
+		return err
+	}
+	if len(pkgs) == 0 {
+		return fmt.Errorf("no importable package matches %s", strings.Join(patterns, " "))
+	}
+	src, err := generate(pkgs, outputFormat)
+	if err != nil {
+		return err
+	}
+	if printMain {
+		_, err = stdout.Write(src)
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "errcodes")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(file, src, 0o644); err != nil {
+		return err
+	}
+	cmd := exec.Command("go", "run", file)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("go run: %v", err)
+	}
+	return nil
+}
+
+// list returns the import paths of the packages matching patterns, leaving
+// out the main packages, which cannot be imported.
+func list(patterns []string, stderr io.Writer) ([]string, error) {
+	args := append([]string{"list", "-f", `{{if ne .Name "main"}}{{.ImportPath}}{{end}}`, "--"}, patterns...)
+	cmd := exec.Command("go", args...)
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %v", err)
+	}
+	return strings.Fields(string(out)), nil
+}
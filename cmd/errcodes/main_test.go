@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func golden(t *testing.T, file string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(file, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s: want:\n%s\ngot:\n%s", file, want, got)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	got, err := generate([]string{"example.com/app/codes", "example.com/app/billing"}, "openapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, filepath.Join("testdata", "main.go.golden"), got)
+
+	if _, err := generate(nil, "yaml"); err == nil || err.Error() != `unknown format "yaml"` {
+		t.Errorf("generate: want: %s, got: %v", `unknown format "yaml"`, err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	for _, format := range []string{"markdown", "json", "openapi"} {
+		t.Run(format, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if err := run([]string{"./testdata/codes"}, format, false, &stdout, &stderr); err != nil {
+				t.Fatalf("%v\n%s", err, stderr.Bytes())
+			}
+			golden(t, filepath.Join("testdata", format+".golden"), stdout.Bytes())
+		})
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"./testdata/codes"}, "markdown", true, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(stdout.Bytes(), []byte(`_ "github.com/shipengqi/errors/cmd/errcodes/testdata/codes"`)) {
+		t.Errorf("run -main: import missing in:\n%s", stdout.Bytes())
+	}
+
+	// The main packages are left out.
+	stdout.Reset()
+	if err := run([]string{".", "./testdata/codes"}, "markdown", true, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(stdout.Bytes(), []byte(`_ "github.com/shipengqi/errors/cmd/errcodes"`)) {
+		t.Errorf("run -main: main package imported in:\n%s", stdout.Bytes())
+	}
+	err := run([]string{"."}, "markdown", true, &stdout, &stderr)
+	if want := "no importable package matches ."; err == nil || err.Error() != want {
+		t.Errorf("run: want: %s, got: %v", want, err)
+	}
+}
@@ -0,0 +1,10 @@
+// Package codes registers the codes printed by the tests of errcodes.
+package codes
+
+import "github.com/shipengqi/errors"
+
+var ErrUserNotFound = errors.Define(20001, "User not found",
+	errors.DefineHTTPStatus(404), errors.DefineReference("https://example.com/errors/20001"))
+
+var ErrUserLocked = errors.DefineTemplate("user_locked", 20002, "User %s locked", []string{"user"},
+	errors.DefineHTTPStatus(423))
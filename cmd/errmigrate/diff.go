@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around the changes of a hunk.
+const diffContext = 3
+
+// maxDiffCells bounds the size of the table computing the longest common
+// subsequence of the changed lines. Beyond it, they are all reported as
+// changed.
+const maxDiffCells = 1 << 22
+
+// diff returns the unified diff between old and new, the contents of name,
+// or nil if they are equal.
+func diff(name string, old, new []byte) []byte {
+	if bytes.Equal(old, new) {
+		return nil
+	}
+	a, b := splitLines(old), splitLines(new)
+	ops := diffLines(a, b)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s.orig\n+++ %s\n", name, name)
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		// extend the hunk to the changes separated by at most 2*diffContext
+		// unchanged lines
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*diffContext {
+				break
+			}
+		}
+		end += diffContext
+		if end > len(ops) {
+			end = len(ops)
+		}
+		writeHunk(&buf, ops[start:end])
+		i = end
+	}
+	return buf.Bytes()
+}
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+	a, b int // line numbers in old and new, from 1
+}
+
+func writeHunk(buf *bytes.Buffer, ops []diffOp) {
+	var na, nb int
+	for _, op := range ops {
+		if op.kind != '+' {
+			na++
+		}
+		if op.kind != '-' {
+			nb++
+		}
+	}
+	fmt.Fprintf(buf, "@@ -%d,%d +%d,%d @@\n", ops[0].a, na, ops[0].b, nb)
+	for _, op := range ops {
+		buf.WriteByte(op.kind)
+		buf.WriteString(op.line)
+		if !strings.HasSuffix(op.line, "\n") {
+			buf.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// diffLines returns the edit script from a to b.
+func diffLines(a, b []string) []diffOp {
+	var ops []diffOp
+	// common prefix and suffix
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+	for i := 0; i < pre; i++ {
+		ops = append(ops, diffOp{' ', a[i], i + 1, i + 1})
+	}
+	ma, mb := a[pre:len(a)-suf], b[pre:len(b)-suf]
+	ops = append(ops, lcsOps(ma, mb, pre+1, pre+1)...)
+	for i := 0; i < suf; i++ {
+		ia, ib := len(a)-suf+i, len(b)-suf+i
+		ops = append(ops, diffOp{' ', a[ia], ia + 1, ib + 1})
+	}
+	return ops
+}
+
+// lcsOps returns the edit script from a to b, keeping their longest common
+// subsequence. la and lb are the line numbers of a[0] and b[0].
+func lcsOps(a, b []string, la, lb int) []diffOp {
+	var ops []diffOp
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		for i, l := range a {
+			ops = append(ops, diffOp{'-', l, la + i, lb})
+		}
+		for i, l := range b {
+			ops = append(ops, diffOp{'+', l, la + len(a), lb + i})
+		}
+		return ops
+	}
+	// lcs[i][j] is the length of the LCS of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i], la + i, lb + j})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', a[i], la + i, lb + j})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j], la + i, lb + j})
+			j++
+		}
+	}
+	return ops
+}
+
+// splitLines splits s after each newline.
+func splitLines(s []byte) []string {
+	lines := strings.SplitAfter(string(s), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
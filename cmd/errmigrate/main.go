@@ -0,0 +1,162 @@
+// Command errmigrate rewrites Go source files to use github.com/shipengqi/errors
+// instead of github.com/pkg/errors, of the standard errors package and of
+// fmt.Errorf.
+//
+// Usage:
+//
+//	errmigrate [flags] [path ...]
+//
+// The paths are files or directories, which are walked recursively, skipping
+// the vendor and testdata directories. Without a path, the current directory
+// is used. The flags are:
+//
+//	-d  print the diffs of the rewritten files instead of the sources
+//	-l  list the rewritten files instead of printing the sources
+//	-w  write the rewritten files in place instead of printing the sources
+//
+// errmigrate makes the following changes:
+//
+//   - imports of github.com/pkg/errors are switched to this package, whose
+//     API is a superset of it;
+//   - the uses of the standard errors package are switched to this package,
+//     except for the sentinel errors created outside of functions and for
+//     the identifiers this package does not provide, for which the standard
+//     package is kept as stderrors;
+//   - in functions, fmt.Errorf("...: %w", args..., err) becomes
+//     errors.Wrapf(err, "...", args...), or errors.Wrap when there are no
+//     other arguments, and fmt.Errorf without %w becomes errors.Errorf.
+//
+// Unlike fmt.Errorf, Wrap and Wrapf return nil when the wrapped error is nil,
+// so fmt.Errorf is only converted in the body of an if statement checking
+// that the wrapped error is not nil, as in if err != nil { ... }.
+// The constructs that cannot be converted are reported on the standard error.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	diffMode  = flag.Bool("d", false, "print diffs instead of the rewritten sources")
+	listMode  = flag.Bool("l", false, "list the files that would be rewritten")
+	writeMode = flag.Bool("w", false, "write the rewritten sources to the files")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: errmigrate [flags] [path ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	opts := options{diff: *diffMode, list: *listMode, write: *writeMode}
+	if err := run(paths, opts, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
+
+type options struct {
+	diff  bool
+	list  bool
+	write bool
+}
+
+// run migrates the Go files of paths, writing the results to stdout and the
+// reports to stderr.
+func run(paths []string, opts options, stdout, stderr io.Writer) error {
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				name := d.Name()
+				if file != path && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.HasSuffix(file, ".go") {
+				return nil
+			}
+			return processFile(file, opts, stdout, stderr)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processFile migrates a Go file.
+func processFile(file string, opts options, stdout, stderr io.Writer) error {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	changed, add, reports := migrate(fset, f)
+	for _, r := range reports {
+		fmt.Fprintln(stderr, r)
+	}
+
+	res := src
+	if changed {
+		var buf bytes.Buffer
+		if err := format.Node(&buf, fset, f); err != nil {
+			return err
+		}
+		res = buf.Bytes()
+	}
+	if add {
+		if res, err = addImport(res); err != nil {
+			return err
+		}
+	}
+	changed = !bytes.Equal(src, res)
+
+	switch {
+	case opts.list || opts.write || opts.diff:
+		if !changed {
+			return nil
+		}
+		if opts.list {
+			fmt.Fprintln(stdout, file)
+		}
+		if opts.write {
+			fi, err := os.Stat(file)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(file, res, fi.Mode().Perm()); err != nil {
+				return err
+			}
+		}
+		if opts.diff {
+			_, err = stdout.Write(diff(filepath.ToSlash(file), src, res))
+		}
+		return err
+	default:
+		_, err = stdout.Write(res)
+		return err
+	}
+}
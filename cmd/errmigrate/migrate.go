@@ -0,0 +1,462 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+const (
+	modulePath    = "github.com/shipengqi/errors"
+	pkgErrorsPath = "github.com/pkg/errors"
+	stdErrorsPath = "errors"
+	fmtPath       = "fmt"
+
+	// stdErrorsName is the name given to the standard errors package when it
+	// is kept next to this package.
+	stdErrorsName = "stderrors"
+)
+
+// supported are the identifiers of the standard errors package that this
+// package provides too.
+var supported = map[string]bool{
+	"New":    true,
+	"Is":     true,
+	"As":     true,
+	"Unwrap": true,
+	"Join":   true,
+}
+
+// migrator rewrites a file to use this package.
+type migrator struct {
+	fset    *token.FileSet
+	file    *ast.File
+	funcs   []*ast.BlockStmt
+	ifs     []*ast.IfStmt
+	name    string // name of this package in the file, if imported
+	changed bool
+	reports []string
+
+	// addImport tells that this package must be imported, see addImport.
+	addImport bool
+}
+
+// migrate rewrites file to use this package instead of github.com/pkg/errors,
+// of the standard errors package and of fmt.Errorf. It reports whether file
+// changed and whether this package must be added to its imports, and returns
+// the constructs it could not convert.
+func migrate(fset *token.FileSet, file *ast.File) (changed, addImport bool, reports []string) {
+	m := &migrator{fset: fset, file: file}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Body != nil {
+				m.funcs = append(m.funcs, n.Body)
+			}
+			return false
+		case *ast.FuncLit:
+			m.funcs = append(m.funcs, n.Body)
+			return false
+		}
+		return true
+	})
+	ast.Inspect(file, func(n ast.Node) bool {
+		if n, ok := n.(*ast.IfStmt); ok {
+			m.ifs = append(m.ifs, n)
+		}
+		return true
+	})
+
+	if spec := m.importSpec(modulePath); spec != nil {
+		m.name = importName(spec)
+	}
+	m.migratePkgErrors()
+	m.migrateStdErrors()
+	m.migrateErrorf()
+	if m.changed {
+		ast.SortImports(fset, file)
+	}
+	return m.changed, m.addImport, m.reports
+}
+
+func (m *migrator) reportf(pos token.Pos, format string, args ...interface{}) {
+	m.reports = append(m.reports, fmt.Sprintf("%s: %s", m.fset.Position(pos), fmt.Sprintf(format, args...)))
+}
+
+// inFunc reports whether n is in the body of a function. The errors created
+// outside of a function are sentinels, which have no use for a stack trace.
+func (m *migrator) inFunc(n ast.Node) bool {
+	for _, body := range m.funcs {
+		if body.Pos() <= n.Pos() && n.End() <= body.End() {
+			return true
+		}
+	}
+	return false
+}
+
+// migratePkgErrors switches the import of github.com/pkg/errors to this
+// package, whose API is a superset of it.
+func (m *migrator) migratePkgErrors() {
+	spec := m.importSpec(pkgErrorsPath)
+	if spec == nil {
+		return
+	}
+	if m.name != "" {
+		m.reportf(spec.Pos(), "cannot convert %s: %s is imported too", pkgErrorsPath, modulePath)
+		return
+	}
+	if spec.Name != nil && spec.Name.Name == "." {
+		m.reportf(spec.Pos(), "cannot convert the dot import of %s", pkgErrorsPath)
+		return
+	}
+	spec.Path.Value = strconv.Quote(modulePath)
+	m.name = importName(spec)
+	m.changed = true
+}
+
+// migrateStdErrors switches the uses of the standard errors package to this
+// package, except for the sentinel errors declared outside of functions and
+// for the identifiers this package does not provide. If some remain, the
+// standard package is kept, renamed to stderrors if it conflicts.
+func (m *migrator) migrateStdErrors() {
+	spec := m.importSpec(stdErrorsPath)
+	if spec == nil {
+		return
+	}
+	name := importName(spec)
+	if name == "_" || name == "." {
+		return
+	}
+
+	var (
+		convert []*ast.SelectorExpr
+		keep    []*ast.SelectorExpr
+	)
+	m.selectors(name, func(sel *ast.SelectorExpr) {
+		switch {
+		case !supported[sel.Sel.Name]:
+			m.reportf(sel.Pos(), "%s.%s has no equivalent in %s, kept the standard errors package", name, sel.Sel.Name, modulePath)
+			keep = append(keep, sel)
+		case sel.Sel.Name == "New" && !m.inFunc(sel):
+			keep = append(keep, sel)
+		default:
+			convert = append(convert, sel)
+		}
+	})
+	if len(convert) == 0 {
+		return
+	}
+	m.changed = true
+
+	if len(keep) == 0 && m.name == "" {
+		spec.Path.Value = strconv.Quote(modulePath)
+		m.name = name
+		return
+	}
+	if len(keep) > 0 && m.name == "" && name == "errors" {
+		spec.Name = &ast.Ident{NamePos: spec.Path.Pos(), Name: stdErrorsName}
+		for _, sel := range keep {
+			sel.X.(*ast.Ident).Name = stdErrorsName
+		}
+	}
+	name = m.ours()
+	for _, sel := range convert {
+		sel.X.(*ast.Ident).Name = name
+	}
+	if len(keep) == 0 {
+		m.deleteImport(spec)
+	}
+}
+
+// migrateErrorf converts the calls to fmt.Errorf made in functions: the calls
+// wrapping an error with a format ending with ": %w" to Wrap or Wrapf, and
+// the calls without %w to Errorf.
+func (m *migrator) migrateErrorf() {
+	spec := m.importSpec(fmtPath)
+	if spec == nil {
+		return
+	}
+	name := importName(spec)
+	if name == "_" || name == "." {
+		return
+	}
+
+	converted := 0
+	ast.Inspect(m.file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || !m.inFunc(call) {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Errorf" || !isIdent(sel.X, name) || len(call.Args) == 0 {
+			return true
+		}
+		if m.convertErrorf(call, sel) {
+			converted++
+		}
+		return true
+	})
+	if converted == 0 {
+		return
+	}
+	m.changed = true
+
+	used := false
+	m.selectors(name, func(*ast.SelectorExpr) { used = true })
+	if !used {
+		m.deleteImport(spec)
+	}
+}
+
+// convertErrorf converts a call to fmt.Errorf, or reports why it cannot.
+func (m *migrator) convertErrorf(call *ast.CallExpr, sel *ast.SelectorExpr) bool {
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		m.reportf(call.Pos(), "cannot convert fmt.Errorf with a non-constant format")
+		return false
+	}
+	format, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return false
+	}
+	verbs, ok := parseVerbs(format)
+	if !ok {
+		m.reportf(call.Pos(), "cannot convert fmt.Errorf with explicit argument indexes")
+		return false
+	}
+	if call.Ellipsis.IsValid() {
+		m.reportf(call.Pos(), "cannot convert fmt.Errorf with variadic arguments")
+		return false
+	}
+
+	wraps := strings.Count(verbs, "w")
+	switch {
+	case wraps == 0:
+		sel.X = ast.NewIdent(m.ours())
+		return true
+	case wraps > 1:
+		m.reportf(call.Pos(), "cannot convert fmt.Errorf wrapping several errors")
+		return false
+	case !strings.HasSuffix(verbs, "w") || !strings.HasSuffix(format, ": %w"):
+		m.reportf(call.Pos(), `cannot convert fmt.Errorf whose format does not end with ": %%w"`)
+		return false
+	case len(call.Args) != len(verbs)+1:
+		m.reportf(call.Pos(), "cannot convert fmt.Errorf with %d verbs and %d arguments", len(verbs), len(call.Args)-1)
+		return false
+	}
+
+	cause := call.Args[len(call.Args)-1]
+	if !m.neverNil(cause) && !m.checkedNonNil(call, cause) {
+		// Wrap returns nil for a nil error, where fmt.Errorf never does.
+		name := types.ExprString(cause)
+		m.reportf(call.Pos(), "cannot convert fmt.Errorf wrapping %s outside of an if %s != nil block", name, name)
+		return false
+	}
+
+	// Trim ": %w" from the literal as written, to keep its quotes and escapes.
+	quote := lit.Value[len(lit.Value)-1:]
+	lit.Value = strings.TrimSuffix(lit.Value, ": %w"+quote) + quote
+	args := call.Args[1 : len(call.Args)-1]
+
+	fn := "Wrapf"
+	if len(args) == 0 && !strings.Contains(format[:len(format)-len(": %w")], "%") {
+		fn = "Wrap"
+	}
+	sel.X = ast.NewIdent(m.ours())
+	sel.Sel = ast.NewIdent(fn)
+	call.Args = append([]ast.Expr{cause, lit}, args...)
+	return true
+}
+
+// checkedNonNil reports whether n is in the body of an if statement whose
+// condition checks that err is not nil, as in if err != nil { ... }.
+func (m *migrator) checkedNonNil(n ast.Node, err ast.Expr) bool {
+	for _, stmt := range m.ifs {
+		if stmt.Body.Pos() <= n.Pos() && n.End() <= stmt.Body.End() && checksNonNil(stmt.Cond, err) {
+			return true
+		}
+	}
+	return false
+}
+
+// neverNil reports whether err is a call to a function that never returns a
+// nil error: New or Errorf of the standard errors package, of fmt or of this
+// package.
+func (m *migrator) neverNil(err ast.Expr) bool {
+	call, ok := err.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || (sel.Sel.Name != "New" && sel.Sel.Name != "Errorf") {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	return ok && (x.Name == m.name || x.Name == fmtPath || x.Name == stdErrorsPath || x.Name == stdErrorsName)
+}
+
+// checksNonNil reports whether cond implies that err is not nil: it is
+// err != nil, possibly combined with other conditions with &&.
+func checksNonNil(cond, err ast.Expr) bool {
+	switch c := ast.Unparen(cond).(type) {
+	case *ast.BinaryExpr:
+		switch c.Op {
+		case token.LAND:
+			return checksNonNil(c.X, err) || checksNonNil(c.Y, err)
+		case token.NEQ:
+			want := types.ExprString(err)
+			return isIdent(c.Y, "nil") && types.ExprString(c.X) == want ||
+				isIdent(c.X, "nil") && types.ExprString(c.Y) == want
+		}
+	}
+	return false
+}
+
+// parseVerbs returns the verbs of format, one letter each. It returns false if
+// format uses explicit argument indexes, which are not supported.
+func parseVerbs(format string) (string, bool) {
+	var verbs []byte
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		j := i + 1
+		for j < len(format) && strings.IndexByte("+-# 0123456789.*[]", format[j]) >= 0 {
+			if format[j] == '[' {
+				return "", false
+			}
+			if format[j] == '*' {
+				verbs = append(verbs, '*')
+			}
+			j++
+		}
+		if j < len(format) && format[j] != '%' {
+			verbs = append(verbs, format[j])
+		}
+		i = j
+	}
+	return string(verbs), true
+}
+
+// importSpec returns the import of path in the file, if any.
+func (m *migrator) importSpec(path string) *ast.ImportSpec {
+	for _, spec := range m.file.Imports {
+		if p, err := strconv.Unquote(spec.Path.Value); err == nil && p == path {
+			return spec
+		}
+	}
+	return nil
+}
+
+// ours returns the name of this package in the file, importing it if needed.
+func (m *migrator) ours() string {
+	if m.name == "" {
+		m.name = "errors"
+		m.addImport = true
+	}
+	return m.name
+}
+
+// deleteImport removes spec from the imports of the file.
+func (m *migrator) deleteImport(spec *ast.ImportSpec) {
+	for i, decl := range m.file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		for j, s := range gen.Specs {
+			if s != spec {
+				continue
+			}
+			gen.Specs = append(gen.Specs[:j], gen.Specs[j+1:]...)
+			if len(gen.Specs) == 0 {
+				m.file.Decls = append(m.file.Decls[:i], m.file.Decls[i+1:]...)
+			} else if len(gen.Specs) == 1 {
+				gen.Lparen = token.NoPos
+			}
+			break
+		}
+	}
+	for i, s := range m.file.Imports {
+		if s == spec {
+			m.file.Imports = append(m.file.Imports[:i], m.file.Imports[i+1:]...)
+			break
+		}
+	}
+}
+
+// selectors calls f for each selector of the package imported as name.
+func (m *migrator) selectors(name string, f func(sel *ast.SelectorExpr)) {
+	ast.Inspect(m.file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok && isIdent(sel.X, name) {
+			f(sel)
+		}
+		return true
+	})
+}
+
+func isIdent(e ast.Expr, name string) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == name
+}
+
+// importName returns the name of the package imported by spec, assuming it
+// is the last element of its path.
+func importName(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	path, _ := strconv.Unquote(spec.Path.Value)
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// addImport adds the import of this package to the formatted source src.
+// It is done on the source rather than on the syntax tree, to put it in a
+// group of its own after the standard library imports as gofmt expects.
+func addImport(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var last *ast.GenDecl
+	for _, decl := range f.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			last = gen
+		}
+	}
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+	path := strconv.Quote(modulePath)
+
+	var buf bytes.Buffer
+	switch {
+	case last == nil:
+		at := offset(f.Name.End())
+		buf.Write(src[:at])
+		buf.WriteString("\n\nimport " + path)
+		buf.Write(src[at:])
+	case last.Lparen.IsValid():
+		// join the group of the last import unless it is the standard library
+		at := offset(last.Rparen)
+		lastPath, _ := strconv.Unquote(last.Specs[len(last.Specs)-1].(*ast.ImportSpec).Path.Value)
+		buf.Write(src[:at])
+		if !strings.Contains(strings.SplitN(lastPath, "/", 2)[0], ".") {
+			buf.WriteString("\n")
+		}
+		buf.WriteString("\t" + path + "\n")
+		buf.Write(src[at:])
+	default:
+		start, end := offset(last.Pos()), offset(last.End())
+		spec := src[offset(last.Specs[0].Pos()):end]
+		buf.Write(src[:start])
+		fmt.Fprintf(&buf, "import (\n\t%s\n\n\t%s\n)", spec, path)
+		buf.Write(src[end:])
+	}
+	return format.Source(buf.Bytes())
+}
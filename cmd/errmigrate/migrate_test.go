@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func golden(t *testing.T, file string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(file, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s: want:\n%s\ngot:\n%s", file, want, got)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	dirs, err := filepath.Glob(filepath.Join("testdata", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range dirs {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			var reports bytes.Buffer
+			for _, file := range files {
+				var out bytes.Buffer
+				if err := processFile(file, options{}, &out, &reports); err != nil {
+					t.Fatal(err)
+				}
+				golden(t, file+".golden", out.Bytes())
+			}
+			got := strings.ReplaceAll(reports.String(), string(filepath.Separator), "/")
+			golden(t, filepath.Join(dir, "reports.golden"), []byte(got))
+		})
+	}
+}
+
+func TestMigrateDiff(t *testing.T) {
+	var out, reports bytes.Buffer
+	file := filepath.Join("testdata", "fmterrorf", "fmterrorf.go")
+	if err := processFile(file, options{diff: true}, &out, &reports); err != nil {
+		t.Fatal(err)
+	}
+	golden(t, filepath.Join("testdata", "fmterrorf", "fmterrorf.diff.golden"), out.Bytes())
+}
+
+func TestMigrateWrite(t *testing.T) {
+	dir := t.TempDir()
+	src, err := os.ReadFile(filepath.Join("testdata", "stdlib", "stdlib.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "stdlib.go")
+	if err := os.WriteFile(file, src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, reports bytes.Buffer
+	if err := run([]string{dir}, options{write: true, list: true}, &out, &reports); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != file+"\n" {
+		t.Errorf("want %q listed, got %q", file, got)
+	}
+	got, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(filepath.Join("testdata", "stdlib", "stdlib.go.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+
+	// a second run has nothing left to migrate
+	out.Reset()
+	if err := run([]string{dir}, options{list: true}, &out, &reports); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("want nothing listed, got %q", out.String())
+	}
+}
+
+func TestParseVerbs(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+		ok     bool
+	}{
+		{"", "", true},
+		{"100%%", "", true},
+		{"%s: %w", "sw", true},
+		{"%+v %-10d %.2f", "vdf", true},
+		{"%*d", "*d", true},
+		{"%[1]v", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseVerbs(tt.format)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseVerbs(%q): want %q, %t, got %q, %t", tt.format, tt.want, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := []byte("a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n")
+	new := []byte("a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn\n")
+	want := `--- x.go.orig
++++ x.go
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -11,3 +11,4 @@
+ k
+ l
+ m
++n
+`
+	if got := string(diff("x.go", old, new)); got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+	if got := diff("x.go", old, old); got != nil {
+		t.Errorf("want no diff, got %q", got)
+	}
+}
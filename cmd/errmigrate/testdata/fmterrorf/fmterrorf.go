@@ -0,0 +1,45 @@
+package fmterrorf
+
+import (
+	"fmt"
+	"os"
+)
+
+var errClosed = fmt.Errorf("closed")
+
+func open(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("open config: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Stat(); err != nil {
+		return fmt.Errorf("stat %s (%d%%): %w", name, 1, err)
+	}
+	if name == "" {
+		return fmt.Errorf("empty name %q", name)
+	}
+	if err := f.Sync(); nil != err && name != "" {
+		return fmt.Errorf("sync %s: %w", name, err)
+	}
+	return errClosed
+}
+
+func nilable(err error) error {
+	if err == nil {
+		return fmt.Errorf("nil: %w", err)
+	}
+	if other := err; other != nil {
+		return fmt.Errorf("other: %w", err)
+	}
+	return fmt.Errorf("read: %w", err)
+}
+
+func unsupported(err, other error, format string, args ...interface{}) error {
+	_ = fmt.Errorf(format, args...)
+	_ = fmt.Errorf("%w: closed", err)
+	_ = fmt.Errorf("%w and %w", err, other)
+	_ = fmt.Errorf("%[1]v: %[1]w", err)
+	fmt.Println("done")
+	return fmt.Errorf(`read: %w`, err)
+}
@@ -0,0 +1,10 @@
+package fmterrorf
+
+import "fmt"
+
+func wrap(err error) error {
+	if err != nil {
+		return fmt.Errorf("wrap: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package pkgerrors
+
+import (
+	"fmt"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+func parse(s string) error {
+	if s == "" {
+		return pkgerrors.New("empty")
+	}
+	return fmt.Errorf("parse %q: %w", s, pkgerrors.New("invalid"))
+}
@@ -0,0 +1,14 @@
+package pkgerrors
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+func read(r io.Reader) error {
+	if _, err := r.Read(nil); err != nil {
+		return errors.Wrap(err, "read")
+	}
+	return errors.New("empty")
+}
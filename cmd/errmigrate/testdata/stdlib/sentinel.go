@@ -0,0 +1,19 @@
+package stdlib
+
+import (
+	"errors"
+	"os"
+)
+
+// ErrNotFound is a sentinel error.
+var ErrNotFound = errors.New("not found")
+
+func find(name string) error {
+	if _, err := os.Stat(name); errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	if name == "" {
+		return errors.New("empty name")
+	}
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,14 @@
+package stdlib
+
+import "errors"
+
+func check(n int) error {
+	if n < 0 {
+		return errors.New("negative")
+	}
+	return nil
+}
+
+func is(err, target error) bool {
+	return errors.Is(err, target)
+}
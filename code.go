@@ -3,6 +3,7 @@ package errors
 import (
 	"fmt"
 	"net/http"
+	"sort"
 	"sync"
 )
 
@@ -50,50 +51,226 @@ var (
 		msg: "Internal server error"}
 	// _codes registered codes.
 	_codes = make(map[int]Coder)
-	mux    = &sync.Mutex{}
+	mux    = &sync.RWMutex{}
 )
 
+// ErrCodeReserved is returned by TryRegister when the code is the Unknown
+// Code, which is reserved by this package.
+type ErrCodeReserved struct {
+	Code int
+}
+
+func (e *ErrCodeReserved) Error() string {
+	return fmt.Sprintf("code `%d` is reserved by `github.com/shipengqi/errors` as Unknown Code", e.Code)
+}
+
+// ErrCodeDuplicate is returned by TryRegister when the code is already
+// registered.
+type ErrCodeDuplicate struct {
+	// Existing is the Coder already registered.
+	Existing Coder
+	// New is the Coder being registered.
+	New Coder
+}
+
+func (e *ErrCodeDuplicate) Error() string {
+	return fmt.Sprintf("code `%d` already registered", e.New.Code())
+}
+
+// ErrCodeOutOfRange is returned by TryRegister when the code of a
+// NamespacedCoder is outside the ranges reserved by its namespace.
+type ErrCodeOutOfRange struct {
+	Coder     NamespacedCoder
+	Namespace string
+}
+
+func (e *ErrCodeOutOfRange) Error() string {
+	return fmt.Sprintf("code `%d` is outside the ranges reserved by namespace `%s`", e.Coder.Code(), e.Namespace)
+}
+
 // Register registers an Coder.
+// If code is a NamespacedCoder, its code must be in a range reserved by its
+// namespace with ReserveRange.
+// Register panics with the message of the error returned by TryRegister.
 func Register(code Coder) {
+	if err := TryRegister(code); err != nil {
+		panic(err.Error())
+	}
+}
+
+// TryRegister registers an Coder like Register, but returns an error instead
+// of panicking: an *ErrCodeReserved, an *ErrCodeDuplicate or an
+// *ErrCodeOutOfRange.
+func TryRegister(code Coder) error {
+	mux.Lock()
+	defer mux.Unlock()
+
+	if err := checkRegister(code, nil); err != nil {
+		return err
+	}
+	_codes[code.Code()] = code
+	return nil
+}
+
+// RegisterAll registers the Coders of codes. It is atomic: if one of them
+// cannot be registered, none is and the error of the first one that cannot be
+// registered is returned, see TryRegister. Two Coders of codes having the
+// same code is an *ErrCodeDuplicate.
+func RegisterAll(codes []Coder) error {
+	mux.Lock()
+	defer mux.Unlock()
+
+	pending := make(map[int]Coder, len(codes))
+	for _, code := range codes {
+		if err := checkRegister(code, pending); err != nil {
+			return err
+		}
+		pending[code.Code()] = code
+	}
+	for c, code := range pending {
+		_codes[c] = code
+	}
+	return nil
+}
+
+// checkRegister returns the error preventing code from being registered, the
+// Coders of pending being about to be. It must be called with mux held.
+func checkRegister(code Coder, pending map[int]Coder) error {
 	if code.Code() == unknownCode.Code() {
-		panic(fmt.Sprintf("code `%d` is reserved by `github.com/shipengqi/errors` as Unknown Code", code.Code()))
+		return &ErrCodeReserved{Code: code.Code()}
+	}
+	if existing, ok := _codes[code.Code()]; ok {
+		return &ErrCodeDuplicate{Existing: existing, New: code}
 	}
-	if _, ok := _codes[code.Code()]; ok {
-		panic(fmt.Sprintf("code `%d` already registered", code.Code()))
+	if existing, ok := pending[code.Code()]; ok {
+		return &ErrCodeDuplicate{Existing: existing, New: code}
+	}
+	return checkNamespace(code)
+}
+
+// Unregister removes the registered Coder of the code of code, if any. The
+// Unknown Code cannot be unregistered.
+func Unregister(code Coder) {
+	if code.Code() == unknownCode.Code() {
+		return
 	}
 	mux.Lock()
 	defer mux.Unlock()
 
-	_codes[code.Code()] = code
+	delete(_codes, code.Code())
+}
+
+// Lookup returns the registered Coder of code, and whether there is one. For
+// code 1, it returns the Unknown Code, which is always registered.
+func Lookup(code int) (Coder, bool) {
+	mux.RLock()
+	defer mux.RUnlock()
+
+	coder, ok := _codes[code]
+	return coder, ok
+}
+
+// Codes returns the registered Coders, including the Unknown Code, sorted by
+// code.
+func Codes() []Coder {
+	mux.RLock()
+	coders := make([]Coder, 0, len(_codes))
+	for _, coder := range _codes {
+		coders = append(coders, coder)
+	}
+	mux.RUnlock()
+
+	sort.Slice(coders, func(i, j int) bool { return coders[i].Code() < coders[j].Code() })
+	return coders
 }
 
 // ParseCoder parse any error into icoder interface.
 // nil error will return nil direct.
-// None withStack error will be parsed as Unknown Code.
+// An error without a registered code is parsed as the fallback Coder, which
+// is the Unknown Code unless set with SetFallbackCoder; how the errors whose
+// code is not registered are handled depends on the FallbackMode.
+// An Aggregate is parsed into the Coder selected by the CoderPolicy among
+// the Coders of its errors.
+// If an error whose code is not registered is found, the hook set with
+// SetUnknownCodeHook is called once with the first such code.
 func ParseCoder(err error) Coder {
 	if err == nil {
 		return nil
 	}
-	if v, ok := err.(icoder); ok {
-		if coder, found := _codes[v.Code()]; found {
-			return coder
+	var (
+		unknown      int
+		foundUnknown bool
+	)
+	coder, ok := parseCoder(err, func(code int) {
+		if !foundUnknown {
+			unknown, foundUnknown = code, true
+		}
+	})
+	if foundUnknown {
+		if hook := unknownCodeHook(); hook != nil {
+			hook(unknown)
 		}
 	}
-	if v, ok := err.(causer); ok {
-		err = v.Cause()
-		return ParseCoder(err)
+	if ok {
+		return coder
 	}
+	return fallbackCoder()
+}
 
-	return unknownCode
+// coderOf returns the Coder of err like ParseCoder, without calling the
+// unknown code hook. It is used by the functions of the package that need
+// the Coder of an error, so that the hook is only called for the calls of
+// ParseCoder.
+func coderOf(err error) Coder {
+	if coder, ok := parseCoder(err, nil); ok {
+		return coder
+	}
+	return fallbackCoder()
+}
+
+// parseCoder returns the registered Coder of err, and false if err is parsed
+// as the fallback Coder. The Unknown Code is never returned. The codes found
+// that are not registered are passed to unknown, if not nil.
+func parseCoder(err error, unknown func(code int)) (Coder, bool) {
+	for err != nil {
+		if v, ok := err.(icoder); ok {
+			coder, found, mode := lookupCode(v.Code())
+			if found {
+				return coder, coder.Code() != unknownCode.Code()
+			}
+			if unknown != nil {
+				unknown(v.Code())
+			}
+			if mode == FallbackToCoder {
+				return nil, false
+			}
+		}
+		if agg, ok := err.(Aggregate); ok {
+			return aggregateCoder(agg, unknown)
+		}
+		v, ok := err.(causer)
+		if !ok {
+			break
+		}
+		err = v.Cause()
+	}
+	return nil, false
 }
 
 // IsCode reports whether any error in err's contains the given code.
+// If err is an Aggregate, IsCode reports whether any of its errors contains
+// the given code.
 func IsCode(err error, code int) bool {
 	if v, ok := err.(icoder); ok {
 		if v.Code() == code {
 			return true
 		}
 	}
+	if agg, ok := err.(Aggregate); ok {
+		return aggregate(agg.Errors()).visit(func(err error) bool {
+			return IsCode(err, code)
+		})
+	}
 	if v, ok := err.(causer); ok {
 		err = v.Cause()
 		return IsCode(err, code)
@@ -102,16 +279,6 @@ func IsCode(err error, code int) bool {
 	return false
 }
 
-//nolint:unused
-func unregister(code Coder) {
-	if _, ok := _codes[code.Code()]; ok {
-		mux.Lock()
-		defer mux.Unlock()
-
-		delete(_codes, code.Code())
-	}
-}
-
 func init() {
 	_codes[unknownCode.Code()] = unknownCode
 }
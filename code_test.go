@@ -2,6 +2,7 @@ package errors
 
 import (
 	"errors"
+	"io"
 	"testing"
 )
 
@@ -12,7 +13,7 @@ func TestRegister(t *testing.T) {
 		msg:    "SUCCESS",
 	}
 	Register(mockSuccessCode)
-	defer unregister(mockSuccessCode)
+	defer Unregister(mockSuccessCode)
 
 	if "SUCCESS" != mockSuccessCode.String() {
 		t.Errorf("code string: want: %s, got: %s", "SUCCESS", mockSuccessCode.String())
@@ -30,7 +31,7 @@ func TestRegister(t *testing.T) {
 			msg:  "SUCCESS",
 		}
 		Register(mockSuccessCode2)
-		defer unregister(mockSuccessCode2)
+		defer Unregister(mockSuccessCode2)
 		if 500 != mockSuccessCode2.HTTPStatus() {
 			t.Errorf("code http status: want: %d, got: %d", 500, mockSuccessCode2.HTTPStatus())
 		}
@@ -71,10 +72,86 @@ func TestRegisterPanic2(t *testing.T) {
 		msg:    "error",
 	}
 	Register(mockErrCode)
-	defer unregister(mockErrCode)
+	defer Unregister(mockErrCode)
 	Register(mockErrCode)
 }
 
+func TestTryRegister(t *testing.T) {
+	existing := defaultCoder{code: 48001, msg: "existing"}
+	if err := TryRegister(existing); err != nil {
+		t.Fatalf("TryRegister: want: nil, got: %v", err)
+	}
+	defer Unregister(existing)
+
+	err := TryRegister(defaultCoder{code: 1, msg: "error"})
+	var reserved *ErrCodeReserved
+	if !As(err, &reserved) || reserved.Code != 1 {
+		t.Errorf("TryRegister: want: *ErrCodeReserved, got: %v", err)
+	}
+
+	dup := defaultCoder{code: 48001, msg: "duplicate"}
+	err = TryRegister(dup)
+	var duplicate *ErrCodeDuplicate
+	if !As(err, &duplicate) || duplicate.Existing != Coder(existing) || duplicate.New != Coder(dup) {
+		t.Errorf("TryRegister: want: *ErrCodeDuplicate, got: %v", err)
+	}
+	if err.Error() != "code `48001` already registered" {
+		t.Errorf("Error: want: %s, got: %s", "code `48001` already registered", err.Error())
+	}
+	if got := ParseCoder(WithCode(io.EOF, 48001)); got != Coder(existing) {
+		t.Errorf("ParseCoder: want: %v, got: %v", existing, got)
+	}
+}
+
+func TestRegisterAll(t *testing.T) {
+	existing := defaultCoder{code: 48011, msg: "existing"}
+	Register(existing)
+	defer Unregister(existing)
+
+	a := defaultCoder{code: 48012, msg: "a"}
+	b := defaultCoder{code: 48013, msg: "b"}
+	tests := []struct {
+		codes []Coder
+		want  string
+	}{
+		{[]Coder{a, existing, b}, "code `48011` already registered"},
+		{[]Coder{a, b, defaultCoder{code: 48012, msg: "again"}}, "code `48012` already registered"},
+		{[]Coder{a, unknownCode}, "code `1` is reserved by `github.com/shipengqi/errors` as Unknown Code"},
+	}
+	for i, tt := range tests {
+		err := RegisterAll(tt.codes)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("test %d: RegisterAll: want: %s, got: %v", i+1, tt.want, err)
+		}
+		for _, code := range []Coder{a, b} {
+			if _, ok := Lookup(code.Code()); ok {
+				t.Errorf("test %d: code %d registered", i+1, code.Code())
+			}
+		}
+	}
+
+	if err := RegisterAll([]Coder{a, b}); err != nil {
+		t.Fatalf("RegisterAll: want: nil, got: %v", err)
+	}
+	for _, code := range []Coder{a, b} {
+		if got, _ := Lookup(code.Code()); got != code {
+			t.Errorf("Lookup(%d): want: %v, got: %v", code.Code(), code, got)
+		}
+		Unregister(code)
+		if _, ok := Lookup(code.Code()); ok {
+			t.Errorf("Unregister(%d): still registered", code.Code())
+		}
+	}
+
+	Unregister(unknownCode)
+	if got := ParseCoder(New("error")); got != Coder(unknownCode) {
+		t.Errorf("ParseCoder: want: %v, got: %v", unknownCode, got)
+	}
+	if _, ok := Lookup(unknownCode.Code()); !ok {
+		t.Errorf("Unregister(unknownCode): unregistered")
+	}
+}
+
 func TestIsCode(t *testing.T) {
 	ok := WithCode(errors.New("ok"), 0)
 	errUnknown := WithCode(errors.New(unknown), 1)
@@ -129,7 +206,7 @@ func TestParseCoder(t *testing.T) {
 		msg:    "SUCCESS",
 	}
 	Register(mockSuccessCode)
-	defer unregister(mockSuccessCode)
+	defer Unregister(mockSuccessCode)
 
 	embedErr1 := WithMessage(WithCode(errors.New("embedded"), 10010), "")
 	err = ParseCoder(embedErr1)
@@ -157,7 +234,7 @@ func TestParseCoderRecursively(t *testing.T) {
 
 	defer func() {
 		for _, v := range codes {
-			unregister(v)
+			Unregister(v)
 		}
 	}()
 
@@ -0,0 +1,93 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// WithComponent annotates err with the name of the component handling it,
+// such as a service or a subsystem. It takes precedence over the Component
+// of the CaptureOptions, see Component.
+// If err is nil, WithComponent returns nil.
+func WithComponent(err error, name string) error {
+	if err == nil {
+		return nil
+	}
+	return &withComponent{
+		cause:     err,
+		component: name,
+	}
+}
+
+type withComponent struct {
+	cause     error
+	component string
+}
+
+func (w *withComponent) Error() string     { return redact(plainMessage(w.cause)) }
+func (w *withComponent) Cause() error      { return w.cause }
+func (w *withComponent) Component() string { return w.component }
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (w *withComponent) Unwrap() error { return w.cause }
+
+func (w *withComponent) plainMessage() string { return plainMessage(w.cause) }
+
+func (w *withComponent) writePlain(out io.Writer) {
+	writePlain(out, w.cause)
+	_, _ = fmt.Fprintf(out, "\ncomponent: %s", w.component)
+}
+
+func (w *withComponent) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			formatPlus(s, w)
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(s, w.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", w.Error())
+	}
+}
+
+// Component returns the component of err, that is the first non-empty
+// component found in err's chain of an error that implements
+//
+//	type componenter interface {
+//	        Component() string
+//	}
+//
+// The component is set by WithComponent, by NewCtx and WrapCtx from a
+// context returned by ContextWithComponent, or recorded according to the
+// CaptureOptions. Component returns an empty string if there is none.
+func Component(err error) string {
+	var name string
+	walk(err, func(err error) bool {
+		if v, ok := err.(interface{ Component() string }); ok {
+			name = v.Component()
+		}
+		return name != ""
+	})
+	return name
+}
+
+type componentKey struct{}
+
+// ContextWithComponent returns a copy of ctx carrying the component name,
+// with which NewCtx and WrapCtx annotate the errors they create.
+func ContextWithComponent(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, componentKey{}, name)
+}
+
+// contextComponent returns the component carried by ctx, if any.
+func contextComponent(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	name, _ := ctx.Value(componentKey{}).(string)
+	return name
+}
@@ -0,0 +1,72 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"testing"
+)
+
+func TestWithComponentNil(t *testing.T) {
+	if got := WithComponent(nil, "api"); got != nil {
+		t.Errorf("WithComponent(nil): want: nil, got: %#v", got)
+	}
+}
+
+func TestComponent(t *testing.T) {
+	SetCaptureOptions(CaptureOptions{Component: "billing"})
+	defer SetCaptureOptions(CaptureOptions{})
+
+	ctx := ContextWithComponent(context.Background(), "checkout")
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{io.EOF, ""},
+		{New("error"), "billing"},
+		{Wrap(io.EOF, "read"), "billing"},
+		{WithComponent(New("error"), "api"), "api"},
+		{Wrap(WithComponent(io.EOF, "api"), "read"), "api"},
+		{WithComponent(WithComponent(io.EOF, "api"), "worker"), "worker"},
+		{NewCtx(ctx, "error"), "checkout"},
+		{WrapCtx(ctx, io.EOF, "read"), "checkout"},
+		{NewCtx(context.Background(), "error"), "billing"},
+	}
+	for i, tt := range tests {
+		if got := Component(tt.err); got != tt.want {
+			t.Errorf("%d: Component: want: %q, got: %q", i, tt.want, got)
+		}
+	}
+}
+
+func TestWithComponentFormat(t *testing.T) {
+	err := WithComponent(New("error"), "api")
+	if got, want := err.Error(), "error"; got != want {
+		t.Errorf("Error(): want: %q, got: %q", want, got)
+	}
+	if !Is(err, err.(*withComponent).cause) {
+		t.Errorf("Is: want: the cause in the chain")
+	}
+	got := fmt.Sprintf("%+v", err)
+	want := "^error\ngithub.com/shipengqi/errors.TestWithComponentFormat\n\t.+/component_test.go:\\d+\n(?s).+\ncomponent: api$"
+	if !regexp.MustCompile(want).MatchString(got) {
+		t.Errorf("Sprintf(%%+v):\n got: %q\nwant: %q", got, want)
+	}
+
+	b, jerr := MarshalError(err)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	var v struct {
+		Component string `json:"component"`
+	}
+	if jerr = json.Unmarshal(b, &v); jerr != nil {
+		t.Fatal(jerr)
+	}
+	if v.Component != "api" {
+		t.Errorf("MarshalError: want: component api, got: %s", b)
+	}
+}
@@ -0,0 +1,109 @@
+package errors
+
+import (
+	"context"
+	"sync"
+)
+
+// ContextExtractor returns the fields of ctx to attach to the errors created
+// by NewCtx and WrapCtx, such as a request ID or a trace ID.
+type ContextExtractor func(ctx context.Context) Fields
+
+var (
+	// _extractors registered context extractors.
+	_extractors  []ContextExtractor
+	extractorsMu sync.RWMutex
+)
+
+// RegisterContextExtractor registers a ContextExtractor. When several
+// extractors return the same field, the one registered last wins.
+func RegisterContextExtractor(fn ContextExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+
+	_extractors = append(_extractors, fn)
+}
+
+// ContextValue returns a ContextExtractor that extracts the value of ctx for
+// key into the field name, if the value is set.
+func ContextValue(name string, key interface{}) ContextExtractor {
+	return func(ctx context.Context) Fields {
+		v := ctx.Value(key)
+		if v == nil {
+			return nil
+		}
+		return Fields{name: v}
+	}
+}
+
+// contextFields returns the fields extracted from ctx by the registered
+// extractors, or nil if there is none.
+func contextFields(ctx context.Context) Fields {
+	if ctx == nil {
+		return nil
+	}
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+
+	var fields Fields
+	for _, fn := range _extractors {
+		for k, v := range fn(ctx) {
+			if fields == nil {
+				fields = make(Fields)
+			}
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+// withContext annotates err with the fields extracted from ctx and with the
+// component carried by ctx, if any.
+func withContext(ctx context.Context, err error) error {
+	if name := contextComponent(ctx); name != "" {
+		err = &withComponent{
+			cause:     err,
+			component: name,
+		}
+	}
+	fields := contextFields(ctx)
+	if len(fields) == 0 {
+		return err
+	}
+	return &withFields{
+		cause:  err,
+		fields: fields,
+	}
+}
+
+// NewCtx returns an error with the supplied message, annotated with the
+// fields extracted from ctx by the registered ContextExtractors and with the
+// component set by ContextWithComponent.
+// NewCtx also records the stack trace at the point it was called.
+func NewCtx(ctx context.Context, message string) error {
+	return withContext(ctx, &fundamental{
+		msg:    message,
+		stack:  callers(),
+		origin: newOrigin(nil),
+	})
+}
+
+// WrapCtx returns an error annotating err with a stack trace at the point
+// WrapCtx is called, the supplied message, the fields extracted from ctx by
+// the registered ContextExtractors, and the component set by
+// ContextWithComponent.
+// If err is nil, WrapCtx returns nil.
+func WrapCtx(ctx context.Context, err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	err = &withMessage{
+		cause: err,
+		msg:   message,
+	}
+	return withContext(ctx, &withStack{
+		err,
+		callers(),
+		newOrigin(err),
+	})
+}
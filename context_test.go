@@ -0,0 +1,97 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+type contextKey string
+
+// withExtractors registers fns for the duration of a test.
+func withExtractors(t *testing.T, fns ...ContextExtractor) {
+	t.Helper()
+	extractorsMu.Lock()
+	saved := _extractors
+	_extractors = nil
+	extractorsMu.Unlock()
+	for _, fn := range fns {
+		RegisterContextExtractor(fn)
+	}
+	t.Cleanup(func() {
+		extractorsMu.Lock()
+		_extractors = saved
+		extractorsMu.Unlock()
+	})
+}
+
+func TestNewCtx(t *testing.T) {
+	withExtractors(t,
+		ContextValue("request_id", contextKey("request")),
+		ContextValue("tenant", contextKey("tenant")),
+	)
+	ctx := context.WithValue(context.Background(), contextKey("request"), "req-1")
+	ctx = context.WithValue(ctx, contextKey("tenant"), 7)
+
+	err := NewCtx(ctx, "error")
+	if got, want := err.Error(), "error"; got != want {
+		t.Errorf("Error(): want: %q, got: %q", want, got)
+	}
+	if got, want := FieldsOf(err), (Fields{"request_id": "req-1", "tenant": 7}); !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldsOf: want: %v, got: %v", want, got)
+	}
+	got := fmt.Sprintf("%+v", err)
+	want := "^error\ngithub.com/shipengqi/errors.TestNewCtx\n\t.+/context_test.go:\\d+\n(?s).+\nfields: request_id=req-1 tenant=7$"
+	if !regexp.MustCompile(want).MatchString(got) {
+		t.Errorf("Sprintf(%%+v):\n got: %q\nwant: %q", got, want)
+	}
+
+	if got := FieldsOf(NewCtx(context.Background(), "error")); got != nil {
+		t.Errorf("FieldsOf: want: nil without values, got: %v", got)
+	}
+}
+
+func TestWrapCtx(t *testing.T) {
+	withExtractors(t,
+		ContextValue("request_id", contextKey("request")),
+		func(ctx context.Context) Fields {
+			return Fields{"trace_id": "trace-1", "request_id": "overridden"}
+		},
+	)
+	ctx := context.WithValue(context.Background(), contextKey("request"), "req-1")
+
+	if got := WrapCtx(ctx, nil, "no error"); got != nil {
+		t.Errorf("WrapCtx(ctx, nil, \"no error\"): got %#v, expected nil", got)
+	}
+
+	err := WrapCtx(ctx, io.EOF, "read")
+	if got, want := err.Error(), "read: EOF"; got != want {
+		t.Errorf("Error(): want: %q, got: %q", want, got)
+	}
+	if got, want := FieldsOf(err), (Fields{"request_id": "overridden", "trace_id": "trace-1"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldsOf: want: %v, got: %v", want, got)
+	}
+	if Cause(err) != io.EOF || !Is(err, io.EOF) {
+		t.Errorf("Cause: want: %v, got: %v", io.EOF, Cause(err))
+	}
+	got := fmt.Sprintf("%+v", err)
+	want := "^EOF\nread\ngithub.com/shipengqi/errors.TestWrapCtx\n\t.+/context_test.go:\\d+\n(?s).+\nfields: request_id=overridden trace_id=trace-1$"
+	if !regexp.MustCompile(want).MatchString(got) {
+		t.Errorf("Sprintf(%%+v):\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestWrapCtxWithoutExtractors(t *testing.T) {
+	withExtractors(t)
+
+	err := WrapCtx(context.Background(), io.EOF, "read")
+	if _, ok := err.(*withStack); !ok {
+		t.Errorf("WrapCtx: want: *withStack, got: %T", err)
+	}
+	if got := FieldsOf(err); got != nil {
+		t.Errorf("FieldsOf: want: nil, got: %v", got)
+	}
+}
@@ -101,8 +101,9 @@ import (
 // New also records the stack trace at the point it was called.
 func New(message string) error {
 	return &fundamental{
-		msg:   message,
-		stack: callers(),
+		msg:    message,
+		stack:  callers(),
+		origin: newOrigin(nil),
 	}
 }
 
@@ -111,34 +112,45 @@ func New(message string) error {
 // Errorf also records the stack trace at the point it was called.
 func Errorf(format string, args ...interface{}) error {
 	return &fundamental{
-		msg:   fmt.Sprintf(format, args...),
-		stack: callers(),
+		msg:    fmt.Sprintf(format, args...),
+		format: format,
+		stack:  callers(),
+		origin: newOrigin(nil),
 	}
 }
 
 // fundamental is an error that has a message and a stack, but no caller.
 type fundamental struct {
-	msg string
+	msg    string
+	format string
 	*stack
+	*origin
 }
 
 func (f *fundamental) Stack() []uintptr { return *f.stack }
 
-func (f *fundamental) Error() string { return f.msg }
+func (f *fundamental) Error() string { return redact(f.msg) }
+
+func (f *fundamental) plainMessage() string { return f.msg }
+
+func (f *fundamental) writePlain(w io.Writer) {
+	_, _ = io.WriteString(w, f.msg)
+	f.origin.format(w)
+	_, _ = fmt.Fprintf(w, "%+v", f.stack)
+}
 
 func (f *fundamental) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		if s.Flag('+') {
-			_, _ = io.WriteString(s, f.msg)
-			f.stack.Format(s, verb)
+			formatPlus(s, f)
 			return
 		}
 		fallthrough
 	case 's':
-		_, _ = io.WriteString(s, f.msg)
+		_, _ = io.WriteString(s, f.Error())
 	case 'q':
-		_, _ = fmt.Fprintf(s, "%q", f.msg)
+		_, _ = fmt.Fprintf(s, "%q", f.Error())
 	}
 }
 
@@ -151,16 +163,28 @@ func WithStack(err error) error {
 	return &withStack{
 		err,
 		callers(),
+		newOrigin(err),
 	}
 }
 
 type withStack struct {
 	error
 	*stack
+	*origin
 }
 
 func (w *withStack) Stack() []uintptr { return *w.stack }
 
+func (w *withStack) Error() string { return redact(w.plainMessage()) }
+
+func (w *withStack) plainMessage() string { return plainMessage(w.error) }
+
+func (w *withStack) writePlain(out io.Writer) {
+	writePlain(out, w.error)
+	w.origin.format(out)
+	_, _ = fmt.Fprintf(out, "%+v", w.stack)
+}
+
 func (w *withStack) Cause() error { return w.error }
 
 // Unwrap provides compatibility for Go 1.13 error chains.
@@ -170,8 +194,7 @@ func (w *withStack) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		if s.Flag('+') {
-			_, _ = fmt.Fprintf(s, "%+v", w.Cause())
-			w.stack.Format(s, verb)
+			formatPlus(s, w)
 			return
 		}
 		fallthrough
@@ -196,6 +219,7 @@ func Wrap(err error, message string) error {
 	return &withStack{
 		err,
 		callers(),
+		newOrigin(err),
 	}
 }
 
@@ -213,6 +237,7 @@ func Wrapf(err error, format string, args ...interface{}) error {
 	return &withStack{
 		err,
 		callers(),
+		newOrigin(err),
 	}
 }
 
@@ -245,9 +270,16 @@ type withMessage struct {
 	msg   string
 }
 
-func (w *withMessage) Error() string { return w.msg + ": " + w.cause.Error() }
+func (w *withMessage) Error() string { return redact(w.plainMessage()) }
 func (w *withMessage) Cause() error  { return w.cause }
 
+func (w *withMessage) plainMessage() string { return w.msg + ": " + plainMessage(w.cause) }
+
+func (w *withMessage) writePlain(out io.Writer) {
+	writePlain(out, w.cause)
+	_, _ = io.WriteString(out, "\n"+w.msg)
+}
+
 // Unwrap provides compatibility for Go 1.13 error chains.
 func (w *withMessage) Unwrap() error { return w.cause }
 
@@ -255,8 +287,7 @@ func (w *withMessage) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		if s.Flag('+') {
-			_, _ = fmt.Fprintf(s, "%+v\n", w.Cause())
-			_, _ = io.WriteString(s, w.msg)
+			formatPlus(s, w)
 			return
 		}
 		fallthrough
@@ -279,6 +310,7 @@ func WrapCode(err error, code int) error {
 	return &withStack{
 		err,
 		callers(),
+		newOrigin(err),
 	}
 }
 
@@ -299,6 +331,7 @@ func WrapCodef(err error, code int, format string, args ...interface{}) error {
 	return &withStack{
 		err,
 		callers(),
+		newOrigin(err),
 	}
 }
 
@@ -333,7 +366,17 @@ type withCode struct {
 	code  int
 }
 
-func (w *withCode) Error() string { return fmt.Sprintf("code: %d, %s", w.code, w.cause.Error()) }
+func (w *withCode) Error() string { return redact(w.plainMessage()) }
+
+func (w *withCode) plainMessage() string {
+	return fmt.Sprintf("code: %d, %s", w.code, plainMessage(w.cause))
+}
+
+func (w *withCode) writePlain(out io.Writer) {
+	_, _ = fmt.Fprintf(out, "code: %d, ", w.code)
+	writePlain(out, w.cause)
+	_, _ = io.WriteString(out, "\n")
+}
 
 func (w *withCode) Cause() error { return w.cause }
 
@@ -346,7 +389,7 @@ func (w *withCode) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		if s.Flag('+') {
-			_, _ = fmt.Fprintf(s, "code: %d, %+v\n", w.code, w.Cause())
+			formatPlus(s, w)
 			return
 		}
 		fallthrough
@@ -376,3 +419,30 @@ func Cause(err error) error {
 	}
 	return err
 }
+
+// walk calls f for err and for each error in its chain until f returns true.
+// The chain is followed through Cause, and through Unwrap for errors that
+// do not implement causer, so that Go 1.13 chains are also visited.
+func walk(err error, f func(error) bool) bool {
+	for err != nil {
+		if f(err) {
+			return true
+		}
+		switch x := err.(type) {
+		case causer:
+			err = x.Cause()
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, e := range x.Unwrap() {
+				if walk(e, f) {
+					return true
+				}
+			}
+			return false
+		default:
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+// Package errorstest provides assertions on the errors of
+// github.com/shipengqi/errors for use in tests.
+//
+// The assertions report their failures with t.Errorf, followed by the %+v
+// output of the offending error, and return whether they succeeded so that
+// the test can stop if it cannot go on:
+//
+//	if !errorstest.AssertCode(t, err, 20010) {
+//	        t.FailNow()
+//	}
+package errorstest
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/shipengqi/errors"
+	"github.com/shipengqi/errors/internal/chain"
+)
+
+// fail reports a failure about err.
+func fail(t testing.TB, err error, format string, args ...interface{}) bool {
+	t.Helper()
+	t.Errorf("%s\nerror:\n%+v", fmt.Sprintf(format, args...), err)
+	return false
+}
+
+// AssertCode asserts that the code of the Coder of err, as returned by
+// errors.ParseCoder, is code.
+func AssertCode(t testing.TB, err error, code int) bool {
+	t.Helper()
+	if err == nil {
+		return fail(t, err, "want an error with code %d, got nil", code)
+	}
+	if got := errors.ParseCoder(err).Code(); got != code {
+		return fail(t, err, "want code %d, got %d", code, got)
+	}
+	return true
+}
+
+// AssertIs asserts that errors.Is(err, target).
+func AssertIs(t testing.TB, err, target error) bool {
+	t.Helper()
+	if !errors.Is(err, target) {
+		return fail(t, err, "want an error matching %q, got %v", target, err)
+	}
+	return true
+}
+
+// AssertAs asserts that errors.As(err, target), which sets target to the
+// matching error.
+func AssertAs(t testing.TB, err error, target interface{}) bool {
+	t.Helper()
+	if !errors.As(err, target) {
+		return fail(t, err, "want an error assignable to %s, got %v", reflect.TypeOf(target).Elem(), err)
+	}
+	return true
+}
+
+// AssertMessageChain asserts that the chain of err has the messages msgs, the
+// outermost first. The message of an error of the chain is the part of its
+// Error that is not the message of its cause, as given to Wrap or
+// WithMessage. The errors that only annotate their cause, such as the ones
+// returned by WithStack and WithCode, are left out.
+func AssertMessageChain(t testing.TB, err error, msgs ...string) bool {
+	t.Helper()
+	got := messageChain(err)
+	if strings.Join(got, "\x00") != strings.Join(msgs, "\x00") || len(got) != len(msgs) {
+		return fail(t, err, "want message chain %q, got %q", msgs, got)
+	}
+	return true
+}
+
+// messageChain returns the messages of the chain of err.
+func messageChain(err error) []string {
+	var msgs []string
+	for err != nil {
+		cause := chain.Next(err)
+		if msg, ok := chain.OwnMessage(err, cause); ok && msg != "" {
+			msgs = append(msgs, msg)
+		}
+		err = cause
+	}
+	return msgs
+}
+
+// AssertStackContains asserts that a stack trace recorded in the chain of err
+// has a frame of the function fn. fn is a function name qualified by its
+// package name, such as "errors.TestFoo" or "http.(*Server).Serve", or by
+// its package path.
+func AssertStackContains(t testing.TB, err error, fn string) bool {
+	t.Helper()
+	var names []string
+	for e := err; e != nil; e = chain.Next(e) {
+		st, ok := e.(interface{ StackTrace() errors.StackTrace })
+		if !ok {
+			continue
+		}
+		for _, f := range st.StackTrace() {
+			name := funcName(f)
+			if name == fn || strings.HasSuffix(name, "/"+fn) {
+				return true
+			}
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return fail(t, err, "want a stack trace with %s, got no stack trace", fn)
+	}
+	return fail(t, err, "want a stack trace with %s, got %s", fn, strings.Join(names, ", "))
+}
+
+func funcName(f errors.Frame) string {
+	pc := uintptr(f) - 1
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		return fn.Name()
+	}
+	return "unknown"
+}
+
+// AssertAggregateLen asserts that err is an errors.Aggregate, or wraps one,
+// with n errors.
+func AssertAggregateLen(t testing.TB, err error, n int) bool {
+	t.Helper()
+	var agg errors.Aggregate
+	if !errors.As(err, &agg) {
+		return fail(t, err, "want an aggregate of %d errors, got %v", n, err)
+	}
+	if got := len(agg.Errors()); got != n {
+		return fail(t, err, "want an aggregate of %d errors, got %d", n, got)
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package errorstest
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/shipengqi/errors"
+)
+
+// recorder is a testing.TB recording the failures instead of reporting them.
+type recorder struct {
+	testing.TB
+	msgs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.msgs = append(r.msgs, fmt.Sprintf(format, args...))
+}
+
+type notFoundCoder struct{}
+
+func (notFoundCoder) Code() int         { return 50001 }
+func (notFoundCoder) HTTPStatus() int   { return 404 }
+func (notFoundCoder) String() string    { return "Not found" }
+func (notFoundCoder) Reference() string { return "" }
+
+func init() {
+	errors.Register(notFoundCoder{})
+}
+
+func newTestError() error {
+	err := errors.Wrap(io.EOF, "read header")
+	return errors.WithCode(errors.WithMessage(err, "parse request"), 50001)
+}
+
+func TestAssertions(t *testing.T) {
+	err := newTestError()
+	agg := errors.Wrap(errors.NewAggregate([]error{io.EOF, os.ErrNotExist}), "batch")
+	var pathErr *os.PathError
+
+	tests := []struct {
+		name   string
+		assert func(t testing.TB) bool
+		want   string // the start of the failure message, if it fails
+	}{
+		{"code", func(t testing.TB) bool { return AssertCode(t, err, 50001) }, ""},
+		{"code mismatch", func(t testing.TB) bool { return AssertCode(t, err, 50002) }, "want code 50002, got 50001"},
+		{"code nil", func(t testing.TB) bool { return AssertCode(t, nil, 50001) }, "want an error with code 50001, got nil"},
+		{"is", func(t testing.TB) bool { return AssertIs(t, err, io.EOF) }, ""},
+		{"is mismatch", func(t testing.TB) bool { return AssertIs(t, err, os.ErrNotExist) }, `want an error matching "file does not exist"`},
+		{"as", func(t testing.TB) bool {
+			return AssertAs(t, errors.Wrap(&os.PathError{Op: "open", Err: io.EOF}, "x"), &pathErr)
+		}, ""},
+		{"as mismatch", func(t testing.TB) bool { return AssertAs(t, err, &pathErr) }, "want an error assignable to *fs.PathError"},
+		{"message chain", func(t testing.TB) bool {
+			return AssertMessageChain(t, err, "parse request", "read header", "EOF")
+		}, ""},
+		{"message chain mismatch", func(t testing.TB) bool {
+			return AssertMessageChain(t, err, "parse request", "EOF")
+		}, `want message chain ["parse request" "EOF"], got ["parse request" "read header" "EOF"]`},
+		{"stack", func(t testing.TB) bool { return AssertStackContains(t, err, "errorstest.newTestError") }, ""},
+		{"stack path", func(t testing.TB) bool {
+			return AssertStackContains(t, err, "github.com/shipengqi/errors/errorstest.newTestError")
+		}, ""},
+		{"stack mismatch", func(t testing.TB) bool { return AssertStackContains(t, err, "errorstest.other") }, "want a stack trace with errorstest.other, got github.com/shipengqi/errors/errorstest.newTestError"},
+		{"no stack", func(t testing.TB) bool { return AssertStackContains(t, io.EOF, "errorstest.other") }, "want a stack trace with errorstest.other, got no stack trace"},
+		{"aggregate", func(t testing.TB) bool { return AssertAggregateLen(t, agg, 2) }, ""},
+		{"aggregate mismatch", func(t testing.TB) bool { return AssertAggregateLen(t, agg, 3) }, "want an aggregate of 3 errors, got 2"},
+		{"not aggregate", func(t testing.TB) bool { return AssertAggregateLen(t, err, 1) }, "want an aggregate of 1 errors, got"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{TB: t}
+			ok := tt.assert(r)
+			if ok != (tt.want == "") {
+				t.Fatalf("want success %t, got %t: %q", tt.want == "", ok, r.msgs)
+			}
+			if tt.want == "" {
+				if len(r.msgs) != 0 {
+					t.Errorf("want no failure, got %q", r.msgs)
+				}
+				return
+			}
+			if len(r.msgs) != 1 {
+				t.Fatalf("want 1 failure, got %q", r.msgs)
+			}
+			if !strings.HasPrefix(r.msgs[0], tt.want) {
+				t.Errorf("want failure starting with %q, got %q", tt.want, r.msgs[0])
+			}
+			if !strings.Contains(r.msgs[0], "\nerror:\n") {
+				t.Errorf("want the error printed, got %q", r.msgs[0])
+			}
+		})
+	}
+}
+
+func TestFailurePrintsStack(t *testing.T) {
+	r := &recorder{TB: t}
+	AssertCode(r, newTestError(), 1)
+	if len(r.msgs) != 1 || !strings.Contains(r.msgs[0], "errorstest.newTestError\n\t") {
+		t.Errorf("want the %%+v output of the error, got %q", r.msgs)
+	}
+}
@@ -0,0 +1,105 @@
+package errors
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// namespaceOf returns the namespace of coder, "" if it is not a
+// NamespacedCoder.
+func namespaceOf(coder Coder) string {
+	if v, ok := coder.(NamespacedCoder); ok {
+		return v.Namespace()
+	}
+	return ""
+}
+
+// ExportMarkdown writes coders, usually Codes(), to w as a Markdown table
+// with their code, HTTP status, message and reference. The table has a
+// namespace column if one of the coders is a NamespacedCoder with a
+// namespace.
+func ExportMarkdown(w io.Writer, coders []Coder) error {
+	withNamespace := false
+	for _, coder := range coders {
+		if namespaceOf(coder) != "" {
+			withNamespace = true
+			break
+		}
+	}
+	cell := strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ").Replace
+
+	bw := bufio.NewWriter(w)
+	if withNamespace {
+		_, _ = io.WriteString(bw, "| Code | HTTP Status | Namespace | Message | Reference |\n")
+		_, _ = io.WriteString(bw, "| ---: | ---: | --- | --- | --- |\n")
+	} else {
+		_, _ = io.WriteString(bw, "| Code | HTTP Status | Message | Reference |\n")
+		_, _ = io.WriteString(bw, "| ---: | ---: | --- | --- |\n")
+	}
+	for _, coder := range coders {
+		_, _ = fmt.Fprintf(bw, "| %d | %d | ", coder.Code(), coder.HTTPStatus())
+		if withNamespace {
+			_, _ = io.WriteString(bw, cell(namespaceOf(coder))+" | ")
+		}
+		_, _ = fmt.Fprintf(bw, "%s | %s |\n", cell(coder.String()), cell(coder.Reference()))
+	}
+	return bw.Flush()
+}
+
+// jsonCoder is the JSON representation of a Coder.
+type jsonCoder struct {
+	Code       int    `json:"code"`
+	HTTPStatus int    `json:"http_status"`
+	Namespace  string `json:"namespace,omitempty"`
+	Message    string `json:"message"`
+	Reference  string `json:"reference,omitempty"`
+}
+
+// ExportJSON writes coders to w as an indented JSON array of objects with
+// their code, HTTP status, namespace, message and reference.
+func ExportJSON(w io.Writer, coders []Coder) error {
+	list := make([]jsonCoder, 0, len(coders))
+	for _, coder := range coders {
+		list = append(list, jsonCoder{
+			Code:       coder.Code(),
+			HTTPStatus: coder.HTTPStatus(),
+			Namespace:  namespaceOf(coder),
+			Message:    coder.String(),
+			Reference:  coder.Reference(),
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(list)
+}
+
+// ExportOpenAPI writes coders to w as the components/responses section of an
+// OpenAPI document in YAML. Each Coder is a response named "Error" followed
+// by its code, described by its message, with its HTTP status in the
+// x-http-status extension and an example of the JSON encoding of its
+// errors. The responses can be referenced from the operations, as in
+// $ref: '#/components/responses/Error20001'.
+func ExportOpenAPI(w io.Writer, coders []Coder) error {
+	bw := bufio.NewWriter(w)
+	_, _ = io.WriteString(bw, "components:\n  responses:\n")
+	for _, coder := range coders {
+		msg := strconv.Quote(coder.String())
+		_, _ = fmt.Fprintf(bw, "    Error%d:\n", coder.Code())
+		_, _ = fmt.Fprintf(bw, "      description: %s\n", msg)
+		_, _ = fmt.Fprintf(bw, "      x-http-status: %d\n", coder.HTTPStatus())
+		if ns := namespaceOf(coder); ns != "" {
+			_, _ = fmt.Fprintf(bw, "      x-namespace: %s\n", strconv.Quote(ns))
+		}
+		if ref := coder.Reference(); ref != "" {
+			_, _ = fmt.Fprintf(bw, "      x-reference: %s\n", strconv.Quote(ref))
+		}
+		_, _ = io.WriteString(bw, "      content:\n        application/json:\n          example:\n")
+		_, _ = fmt.Fprintf(bw, "            code: %d\n", coder.Code())
+		_, _ = fmt.Fprintf(bw, "            message: %s\n", msg)
+	}
+	return bw.Flush()
+}
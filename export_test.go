@@ -0,0 +1,88 @@
+package errors
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCodes(t *testing.T) {
+	a := defaultCoder{code: 49002, msg: "b"}
+	b := defaultCoder{code: 49001, msg: "a"}
+	if err := RegisterAll([]Coder{a, b}); err != nil {
+		t.Fatal(err)
+	}
+	defer Unregister(a)
+	defer Unregister(b)
+
+	codes := Codes()
+	for i := 1; i < len(codes); i++ {
+		if codes[i-1].Code() >= codes[i].Code() {
+			t.Errorf("Codes: not sorted: %d before %d", codes[i-1].Code(), codes[i].Code())
+		}
+	}
+	var got []Coder
+	for _, coder := range codes {
+		switch coder.Code() {
+		case 1, 49001, 49002:
+			got = append(got, coder)
+		}
+	}
+	if want := []Coder{unknownCode, b, a}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Codes: want: %v, got: %v", want, got)
+	}
+
+	if coder, ok := Lookup(49001); !ok || coder != Coder(b) {
+		t.Errorf("Lookup: want: %v, got: %v, %t", b, coder, ok)
+	}
+	if coder, ok := Lookup(49003); ok || coder != nil {
+		t.Errorf("Lookup: want: nil, got: %v, %t", coder, ok)
+	}
+}
+
+func TestExport(t *testing.T) {
+	coders := []Coder{
+		unknownCode,
+		defaultCoder{code: 20001, status: 404, msg: "User not found", ref: "https://example.com/errors/20001"},
+		defaultCoder{code: 20002, status: 409, msg: "Name | alias conflict"},
+	}
+	namespaced := append(coders,
+		namespacedCoder{defaultCoder{code: 30001, status: 402, msg: "Payment declined"}, "billing"})
+
+	tests := []struct {
+		name   string
+		export func(w io.Writer, coders []Coder) error
+		coders []Coder
+	}{
+		{"markdown.md", ExportMarkdown, coders},
+		{"namespaced.md", ExportMarkdown, namespaced},
+		{"codes.json", ExportJSON, namespaced},
+		{"openapi.yaml", ExportOpenAPI, namespaced},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.export(&buf, tt.coders); err != nil {
+				t.Fatal(err)
+			}
+			got := buf.String()
+			file := filepath.Join("testdata", "export", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(file, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("%s: want:\n%s\ngot:\n%s", file, want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package errors
+
+// FallbackMode tells ParseCoder what to do when it finds an error whose code
+// is not registered.
+type FallbackMode int
+
+const (
+	// FallbackToAncestor skips the errors whose code is not registered, and
+	// returns the Coder of the nearest error of the chain below them whose
+	// code is registered, or the fallback Coder if there is none. It is the
+	// default.
+	FallbackToAncestor FallbackMode = iota
+	// FallbackToCoder returns the fallback Coder as soon as an error whose
+	// code is not registered is found.
+	FallbackToCoder
+)
+
+var (
+	fallback     Coder = unknownCode
+	fallbackMode       = FallbackToAncestor
+	unknownHook  func(code int)
+)
+
+// SetFallbackCoder sets the Coder returned by ParseCoder for the errors
+// without a registered code, which is the Unknown Code (code 1, HTTP status
+// 500) by default. A nil coder restores the Unknown Code.
+func SetFallbackCoder(coder Coder) {
+	if coder == nil {
+		coder = unknownCode
+	}
+	mux.Lock()
+	defer mux.Unlock()
+
+	fallback = coder
+}
+
+// SetFallbackMode sets the FallbackMode of ParseCoder.
+func SetFallbackMode(mode FallbackMode) {
+	mux.Lock()
+	defer mux.Unlock()
+
+	fallbackMode = mode
+}
+
+// SetUnknownCodeHook sets a function called by ParseCoder when it finds an
+// error whose code is not registered, for example to count them in metrics.
+// It is called at most once per call of ParseCoder, with the first such code.
+// The other functions of the package, such as the JSON encoding, do not call
+// it. The hook must be safe for concurrent use. A nil hook removes it.
+func SetUnknownCodeHook(hook func(code int)) {
+	mux.Lock()
+	defer mux.Unlock()
+
+	unknownHook = hook
+}
+
+// fallbackCoder returns the fallback Coder.
+func fallbackCoder() Coder {
+	mux.RLock()
+	defer mux.RUnlock()
+
+	return fallback
+}
+
+// unknownCodeHook returns the hook set with SetUnknownCodeHook.
+func unknownCodeHook() func(code int) {
+	mux.RLock()
+	defer mux.RUnlock()
+
+	return unknownHook
+}
+
+// lookupCode returns the registered Coder of code like Lookup, and the
+// FallbackMode.
+func lookupCode(code int) (Coder, bool, FallbackMode) {
+	mux.RLock()
+	defer mux.RUnlock()
+
+	coder, ok := _codes[code]
+	return coder, ok, fallbackMode
+}
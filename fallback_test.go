@@ -0,0 +1,99 @@
+package errors
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestSetFallbackCoder(t *testing.T) {
+	custom := defaultCoder{code: 50000, status: 503, msg: "Service unavailable"}
+	SetFallbackCoder(custom)
+	defer SetFallbackCoder(nil)
+
+	registered := defaultCoder{code: 50001, status: 404, msg: "not found"}
+	Register(registered)
+	defer Unregister(registered)
+
+	tests := []struct {
+		err  error
+		want Coder
+	}{
+		{io.EOF, custom},
+		{New("error"), custom},
+		{WithCode(io.EOF, 50099), custom},
+		{WithCode(io.EOF, 1), custom},
+		{WithCode(io.EOF, 50001), registered},
+		{NewAggregate([]error{io.EOF, New("error")}), custom},
+	}
+	for i, tt := range tests {
+		if got := ParseCoder(tt.err); got != tt.want {
+			t.Errorf("test %d: ParseCoder: want: %v, got: %v", i+1, tt.want, got)
+		}
+	}
+	if !MatchHTTPStatus(503, 503)(io.EOF) {
+		t.Errorf("MatchHTTPStatus: want: true, got: false")
+	}
+
+	SetFallbackCoder(nil)
+	if got := ParseCoder(io.EOF); got != Coder(unknownCode) {
+		t.Errorf("ParseCoder: want: %v, got: %v", unknownCode, got)
+	}
+}
+
+func TestSetFallbackMode(t *testing.T) {
+	defer SetFallbackMode(FallbackToAncestor)
+
+	registered := defaultCoder{code: 50011, status: 404, msg: "not found"}
+	Register(registered)
+	defer Unregister(registered)
+
+	tests := []struct {
+		err  error
+		mode FallbackMode
+		want Coder
+	}{
+		{WithCode(WithCode(io.EOF, 50011), 50099), FallbackToAncestor, registered},
+		{WithCode(WithCode(io.EOF, 50011), 50099), FallbackToCoder, unknownCode},
+		{Wrap(WithCode(io.EOF, 50011), "read"), FallbackToCoder, registered},
+		{WithCode(WithCode(io.EOF, 50099), 50011), FallbackToCoder, registered},
+		{NewAggregate([]error{WithCode(io.EOF, 50099), WithCode(io.EOF, 50011)}), FallbackToCoder, registered},
+	}
+	for i, tt := range tests {
+		SetFallbackMode(tt.mode)
+		if got := ParseCoder(tt.err); got != tt.want {
+			t.Errorf("test %d: ParseCoder: want: %v, got: %v", i+1, tt.want, got)
+		}
+	}
+}
+
+func TestSetUnknownCodeHook(t *testing.T) {
+	var got []int
+	SetUnknownCodeHook(func(code int) { got = append(got, code) })
+	defer SetUnknownCodeHook(nil)
+
+	registered := defaultCoder{code: 50021, status: 404, msg: "not found"}
+	Register(registered)
+	defer Unregister(registered)
+
+	ParseCoder(WithCode(WithCode(io.EOF, 50021), 50098))
+	ParseCoder(WithCode(io.EOF, 50021))
+	ParseCoder(NewAggregate([]error{WithCode(io.EOF, 50097), io.EOF}))
+	ParseCoder(New("error"))
+	ParseCoder(Wrap(WithCode(WithCode(io.EOF, 50095), 50094), "a"))
+
+	err := Wrap(WithMessage(Wrap(WithCode(io.EOF, 50093), "a"), "b"), "c")
+	MatchHTTPStatus(500, 599)(err)
+	_, _ = MarshalError(err)
+	PublicMessage(err)
+
+	if want := []int{50098, 50097, 50094}; !reflect.DeepEqual(got, want) {
+		t.Errorf("hook: want: %v, got: %v", want, got)
+	}
+
+	SetUnknownCodeHook(nil)
+	ParseCoder(WithCode(io.EOF, 50096))
+	if len(got) != 3 {
+		t.Errorf("hook: removed hook called: %v", got)
+	}
+}
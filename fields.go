@@ -0,0 +1,99 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Fields is a set of key/value pairs annotating an error, such as the
+// parameters of its message.
+type Fields map[string]interface{}
+
+// WithFields annotates err with fields.
+// If err is nil, WithFields returns nil.
+func WithFields(err error, fields Fields) error {
+	if err == nil {
+		return nil
+	}
+	return &withFields{
+		cause:  err,
+		fields: fields,
+	}
+}
+
+type withFields struct {
+	cause  error
+	fields Fields
+}
+
+func (w *withFields) Error() string  { return redact(plainMessage(w.cause)) }
+func (w *withFields) Cause() error   { return w.cause }
+func (w *withFields) Fields() Fields { return w.fields }
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (w *withFields) Unwrap() error { return w.cause }
+
+func (w *withFields) plainMessage() string { return plainMessage(w.cause) }
+
+func (w *withFields) writePlain(out io.Writer) {
+	writePlain(out, w.cause)
+	_, _ = fmt.Fprintf(out, "\nfields: %s", w.fields)
+}
+
+func (w *withFields) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			formatPlus(s, w)
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(s, w.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", w.Error())
+	}
+}
+
+// String formats the fields as space separated key=value pairs, sorted by key.
+func (f Fields) String() string {
+	keys := make([]string, 0, len(f))
+	for k := range f {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", k, f[k]))
+	}
+	return strings.Join(pairs, " ")
+}
+
+type fielder interface {
+	Fields() Fields
+}
+
+// FieldsOf returns the fields of all the errors in err's chain, merged into
+// a new Fields. When a key is set more than once, the outermost value wins.
+// FieldsOf returns nil if no error in the chain has fields.
+func FieldsOf(err error) Fields {
+	var fields Fields
+	walk(err, func(err error) bool {
+		v, ok := err.(fielder)
+		if !ok {
+			return false
+		}
+		for k, value := range v.Fields() {
+			if fields == nil {
+				fields = make(Fields)
+			}
+			if _, exists := fields[k]; !exists {
+				fields[k] = value
+			}
+		}
+		return false
+	})
+	return fields
+}
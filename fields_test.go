@@ -0,0 +1,59 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestFieldsOf(t *testing.T) {
+	runs := []struct {
+		expected Fields
+		err      error
+	}{
+		{nil, nil},
+		{nil, io.EOF},
+		{Fields{"user": "alice"}, WithFields(io.EOF, Fields{"user": "alice"})},
+		{Fields{"user": "alice"}, Wrap(WithFields(io.EOF, Fields{"user": "alice"}), "read")},
+		{Fields{"user": "alice"}, fmt.Errorf("read: %w", WithFields(io.EOF, Fields{"user": "alice"}))},
+		{
+			Fields{"user": "bob", "tenant": 7},
+			WithFields(WithFields(io.EOF, Fields{"user": "alice", "tenant": 7}), Fields{"user": "bob"}),
+		},
+	}
+	for i, r := range runs {
+		if got := FieldsOf(r.err); !reflect.DeepEqual(got, r.expected) {
+			t.Errorf("%d: FieldsOf: want: %v, got: %v", i, r.expected, got)
+		}
+	}
+}
+
+func TestFieldsOfDoesNotModifyFields(t *testing.T) {
+	inner := Fields{"user": "alice"}
+	got := FieldsOf(WithFields(WithFields(io.EOF, inner), Fields{"tenant": 7}))
+	got["user"] = "bob"
+	if inner["user"] != "alice" || len(inner) != 1 {
+		t.Errorf("FieldsOf modified the fields of the chain: %v", inner)
+	}
+}
+
+func TestWithFieldsNil(t *testing.T) {
+	if got := WithFields(nil, Fields{"user": "alice"}); got != nil {
+		t.Errorf("WithFields(nil, fields): got %#v, expected nil", got)
+	}
+}
+
+func TestFormatWithFields(t *testing.T) {
+	err := WithFields(io.EOF, Fields{"user": "alice", "tenant": 7})
+	for format, want := range map[string]string{
+		"%s":  "EOF",
+		"%v":  "EOF",
+		"%q":  `"EOF"`,
+		"%+v": "EOF\nfields: tenant=7 user=alice",
+	} {
+		if got := fmt.Sprintf(format, err); got != want {
+			t.Errorf("Sprintf(%q): want: %q, got: %q", format, want, got)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+//go:build go1.18
+
+package errors
+
+// MatchAs returns a Matcher that reports whether any error in the chain
+// matches the type T, as reported by As.
+//
+// As with As, T must be a type that implements error, or any interface type.
+func MatchAs[T any]() Matcher {
+	return func(err error) bool {
+		return walk(err, func(err error) bool {
+			var target T
+			return As(err, &target)
+		})
+	}
+}
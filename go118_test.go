@@ -0,0 +1,39 @@
+//go:build go1.18
+// +build go1.18
+
+package errors
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"testing"
+)
+
+func TestMatchAs(t *testing.T) {
+	pathErr := &fs.PathError{Op: "open", Path: "/tmp", Err: io.EOF}
+	runs := []struct {
+		expected bool
+		err      error
+	}{
+		{true, pathErr},
+		{true, Wrap(pathErr, "read")},
+		{true, fmt.Errorf("read: %w", pathErr)},
+		{true, causeOnly{pathErr}},
+		{false, io.EOF},
+	}
+	for i, r := range runs {
+		if got := MatchAs[*fs.PathError]()(r.err); got != r.expected {
+			t.Errorf("%d: MatchAs: want: %v, got: %v", i, r.expected, got)
+		}
+	}
+
+	if !MatchAs[interface{ Timeout() bool }]()(Wrap(timeoutError{}, "dial")) {
+		t.Error("MatchAs: want: true, got: false")
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string { return "timeout" }
+func (timeoutError) Timeout() bool { return true }
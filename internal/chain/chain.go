@@ -0,0 +1,38 @@
+// Package chain holds the steps shared by the packages walking the chain of
+// an error one link at a time.
+package chain
+
+import "strings"
+
+// Next returns the cause of err, following Cause first and then Unwrap.
+func Next(err error) error {
+	switch x := err.(type) {
+	case interface{ Cause() error }:
+		return x.Cause()
+	case interface{ Unwrap() error }:
+		return x.Unwrap()
+	}
+	return nil
+}
+
+// OwnMessage returns the part of the message of err that is not the message
+// of its cause. It returns false if err only annotates its cause, that is if
+// both messages are the same or if err has a code and a message of its own
+// that does not end with the message of its cause.
+func OwnMessage(err, cause error) (string, bool) {
+	msg := err.Error()
+	if cause == nil {
+		return msg, true
+	}
+	cmsg := cause.Error()
+	if msg == cmsg {
+		return "", false
+	}
+	if strings.HasSuffix(msg, ": "+cmsg) {
+		return strings.TrimSuffix(msg, ": "+cmsg), true
+	}
+	if _, ok := err.(interface{ Code() int }); ok {
+		return "", false
+	}
+	return msg, true
+}
@@ -0,0 +1,62 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+type causeError struct{ cause error }
+
+func (e causeError) Error() string { return "cause: " + e.cause.Error() }
+func (e causeError) Cause() error  { return e.cause }
+func (e causeError) Unwrap() error { return io.ErrUnexpectedEOF }
+
+type codeError struct {
+	msg   string
+	cause error
+}
+
+func (e codeError) Error() string { return e.msg }
+func (e codeError) Code() int     { return 20001 }
+func (e codeError) Unwrap() error { return e.cause }
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		err  error
+		want error
+	}{
+		{io.EOF, nil},
+		{fmt.Errorf("read: %w", io.EOF), io.EOF},
+		{causeError{io.EOF}, io.EOF},
+		{errors.New("error"), nil},
+	}
+	for i, tt := range tests {
+		if got := Next(tt.err); got != tt.want {
+			t.Errorf("%d: Next: want: %v, got: %v", i, tt.want, got)
+		}
+	}
+}
+
+func TestOwnMessage(t *testing.T) {
+	tests := []struct {
+		err    error
+		cause  error
+		want   string
+		wantOK bool
+	}{
+		{io.EOF, nil, "EOF", true},
+		{fmt.Errorf("read: %w", io.EOF), io.EOF, "read", true},
+		{fmt.Errorf("%w", io.EOF), io.EOF, "", false},
+		{fmt.Errorf("read failed (%w)", io.EOF), io.EOF, "read failed (EOF)", true},
+		{codeError{"code: 20001, EOF", io.EOF}, io.EOF, "", false},
+		{codeError{"code: 20001: EOF", io.EOF}, io.EOF, "code: 20001", true},
+	}
+	for i, tt := range tests {
+		got, ok := OwnMessage(tt.err, tt.cause)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%d: OwnMessage: want: %q, %v, got: %q, %v", i, tt.want, tt.wantOK, got, ok)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package errors
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// jsonError is the JSON representation of an error chain.
+type jsonError struct {
+	ID        string       `json:"id,omitempty"`
+	Message   string       `json:"message"`
+	Code      int          `json:"code,omitempty"`
+	Template  string       `json:"template,omitempty"`
+	Severity  Severity     `json:"severity"`
+	Time      *time.Time   `json:"time,omitempty"`
+	Goroutine uint64       `json:"goroutine,omitempty"`
+	Component string       `json:"component,omitempty"`
+	Fields    Fields       `json:"fields,omitempty"`
+	Stack     StackTrace   `json:"stack,omitempty"`
+	Errors    []*jsonError `json:"errors,omitempty"`
+}
+
+// newJSONError returns the JSON representation of err. The stack is the one
+// recorded closest to the origin of the error, and errors lists the errors
+// of the first Aggregate in the chain.
+func newJSONError(err error) *jsonError {
+	je := &jsonError{
+		ID:       ID(err),
+		Message:  redact(plainMessage(err)),
+		Code:     coderOf(err).Code(),
+		Template: TemplateOf(err),
+		Severity: SeverityOf(err),
+		Fields:   redactFields(FieldsOf(err)),
+	}
+	je.Component = Component(err)
+	if o := originOf(err); o != nil {
+		if !o.time.IsZero() {
+			je.Time = &o.time
+		}
+		je.Goroutine = o.goroutine
+	}
+	var agg Aggregate
+	walk(err, func(err error) bool {
+		if v, ok := err.(interface{ StackTrace() StackTrace }); ok {
+			je.Stack = v.StackTrace()
+		}
+		if v, ok := err.(Aggregate); ok && agg == nil {
+			agg = v
+		}
+		return false
+	})
+	if agg != nil {
+		for _, e := range agg.Errors() {
+			je.Errors = append(je.Errors, newJSONError(e))
+		}
+	}
+	return je
+}
+
+// redactFields applies the Redactor to the string and error values of fields.
+func redactFields(fields Fields) Fields {
+	for k, v := range fields {
+		switch x := v.(type) {
+		case string:
+			fields[k] = redact(x)
+		case error:
+			fields[k] = redact(x.Error())
+		}
+	}
+	return fields
+}
+
+// MarshalError encodes the chain of err as a JSON object with its message,
+// instance ID, code, template, severity, fields, stack trace and the
+// information recorded according to the CaptureOptions. The errors of the
+// first Aggregate in the chain are encoded in the errors member.
+// If err is nil, MarshalError returns null.
+//
+// The errors of this package do not implement json.Marshaler: the JSON
+// encoding is opt-in, so that the internals of an error held by a value
+// encoded with encoding/json, such as an API response, are not disclosed.
+func MarshalError(err error) ([]byte, error) {
+	if err == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(newJSONError(err))
+}
@@ -2,7 +2,9 @@ package errors
 
 import (
 	"encoding/json"
+	"io"
 	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -49,3 +51,110 @@ func TestFrameMarshalJSON(t *testing.T) {
 		}
 	}
 }
+
+func TestMarshalError(t *testing.T) {
+	mockCode := defaultCoder{code: 20001, status: 404, msg: "not found"}
+	Register(mockCode)
+	defer Unregister(mockCode)
+
+	tests := []struct {
+		err      error
+		message  string
+		code     int
+		severity string
+		stack    bool
+	}{
+		{New("error"), "error", 1, "error", true},
+		{Wrap(io.EOF, "read"), "read: EOF", 1, "error", true},
+		{WithMessage(io.EOF, "read"), "read: EOF", 1, "error", false},
+		{WithCode(New("error"), 20001), "code: 20001, error", 20001, "error", true},
+		{WithSeverity(WithStack(io.EOF), SeverityWarn), "EOF", 1, "warn", true},
+		{WithRetryable(io.EOF, true), "EOF", 1, "error", false},
+	}
+	for i, tt := range tests {
+		got, err := MarshalError(tt.err)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v struct {
+			Message  string   `json:"message"`
+			Code     int      `json:"code"`
+			Severity string   `json:"severity"`
+			Stack    []string `json:"stack"`
+		}
+		if err := json.Unmarshal(got, &v); err != nil {
+			t.Fatalf("test %d: %v: %s", i+1, err, got)
+		}
+		if v.Message != tt.message || v.Code != tt.code || v.Severity != tt.severity || (len(v.Stack) > 0) != tt.stack {
+			t.Errorf("test %d: MarshalError: unexpected %s", i+1, got)
+		}
+	}
+}
+
+func TestMarshalErrorOptIn(t *testing.T) {
+	got, err := json.Marshal(struct {
+		Err error
+	}{Wrap(WithFields(New("secret"), Fields{"user": "alice"}), "read")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"Err":{}}`; string(got) != want {
+		t.Errorf("json.Marshal:\n got %s\n want %s", got, want)
+	}
+
+	got, err = MarshalError(nil)
+	if err != nil || string(got) != "null" {
+		t.Errorf("MarshalError(nil): want: null, got: %s, %v", got, err)
+	}
+}
+
+func TestMarshalErrorStack(t *testing.T) {
+	got, err := MarshalError(Wrap(New("error"), "wrapped"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v struct {
+		Stack []string `json:"stack"`
+	}
+	if err := json.Unmarshal(got, &v); err != nil {
+		t.Fatal(err)
+	}
+	want := `^github.com/shipengqi/errors\.TestMarshalErrorStack .+/json_test.go:\d+$`
+	if len(v.Stack) == 0 || !regexp.MustCompile(want).MatchString(v.Stack[0]) {
+		t.Errorf("MarshalError: stack:\n got %q\n want %q", v.Stack, want)
+	}
+}
+
+func TestMarshalErrorAggregate(t *testing.T) {
+	agg := NewAggregate([]error{WithSeverity(io.EOF, SeverityWarn), New("error")})
+	got, err := MarshalError(agg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v struct {
+		Message  string `json:"message"`
+		Severity string `json:"severity"`
+		Errors   []struct {
+			Message  string `json:"message"`
+			Severity string `json:"severity"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(got, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Message != "[EOF, error]" || v.Severity != "error" || len(v.Errors) != 2 ||
+		v.Errors[0].Message != "EOF" || v.Errors[0].Severity != "warn" {
+		t.Errorf("MarshalError: unexpected %s", got)
+	}
+}
+
+func TestMarshalErrorFields(t *testing.T) {
+	got, err := MarshalError(Wrap(WithFields(io.EOF, Fields{"user": "alice", "tenant": 7}), "read"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `"fields":{"tenant":7,"user":"alice"}`
+	if !strings.Contains(string(got), want) {
+		t.Errorf("MarshalError:\n got %s\n want %s", got, want)
+	}
+}
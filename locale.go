@@ -0,0 +1,290 @@
+package errors
+
+import (
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// LocalizedCoder is a Coder that provides its message in several languages.
+//
+// The messages are text/template templates executed with the fields of the
+// error, see FieldsOf. For example
+//
+//	Benutzer {{.user}} wurde nicht gefunden
+type LocalizedCoder interface {
+	Coder
+
+	// LocalizedString returns the message template for the language tag
+	// lang, such as "de" or "pt-BR", and false if there is none.
+	LocalizedString(lang string) (string, bool)
+}
+
+// Catalog holds the localized message templates of codes, per language.
+// A Catalog is safe for concurrent use.
+type Catalog struct {
+	mu       sync.RWMutex
+	messages map[string]map[int]*template.Template
+}
+
+// DefaultCatalog is the Catalog used by Message.
+var DefaultCatalog = NewCatalog()
+
+// NewCatalog returns an empty Catalog.
+func NewCatalog() *Catalog {
+	return &Catalog{messages: make(map[string]map[int]*template.Template)}
+}
+
+// Set sets the message template of code for the language tag lang.
+func (c *Catalog) Set(lang string, code int, message string) error {
+	tmpl, err := parseMessage(message)
+	if err != nil {
+		return Wrapf(err, "parse message of code `%d` for %q", code, lang)
+	}
+	c.store(lang, map[int]*template.Template{code: tmpl})
+	return nil
+}
+
+// Load sets the message templates for the language tag lang, read from r as
+// a JSON object that maps codes to templates. For example
+//
+//	{
+//	  "20010": "Benutzer {{.user}} wurde nicht gefunden"
+//	}
+//
+// If a code or a template is invalid, Load returns an error and sets none of
+// the templates.
+func (c *Catalog) Load(lang string, r io.Reader) error {
+	var messages map[string]string
+	if err := json.NewDecoder(r).Decode(&messages); err != nil {
+		return Wrapf(err, "decode messages for %q", lang)
+	}
+	tmpls := make(map[int]*template.Template, len(messages))
+	for key, message := range messages {
+		code, err := strconv.Atoi(key)
+		if err != nil {
+			return Wrapf(err, "invalid code %q for %q", key, lang)
+		}
+		tmpl, err := parseMessage(message)
+		if err != nil {
+			return Wrapf(err, "parse message of code `%d` for %q", code, lang)
+		}
+		tmpls[code] = tmpl
+	}
+	c.store(lang, tmpls)
+	return nil
+}
+
+// store sets the message templates of lang.
+func (c *Catalog) store(lang string, tmpls map[int]*template.Template) {
+	lang = canonicalLanguage(lang)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.messages[lang] == nil {
+		c.messages[lang] = make(map[int]*template.Template)
+	}
+	for code, tmpl := range tmpls {
+		c.messages[lang][code] = tmpl
+	}
+}
+
+// LoadFile loads the message templates of the file with the given path, see
+// Load. The language tag is the last dot separated element of the file name
+// without extension, for example "de" for "messages.de.json".
+func (c *Catalog) LoadFile(name string) error {
+	f, err := os.Open(filepath.Clean(name))
+	if err != nil {
+		return WithStack(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	return c.Load(languageOfFile(name), f)
+}
+
+// LoadFS loads the message templates of the files of fsys matching pattern,
+// see LoadFile.
+func (c *Catalog) LoadFS(fsys fs.FS, pattern string) error {
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return WithStack(err)
+	}
+	for _, name := range names {
+		f, err := fsys.Open(name)
+		if err != nil {
+			return WithStack(err)
+		}
+		err = c.Load(languageOfFile(name), f)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Languages returns the sorted language tags that have messages.
+func (c *Catalog) Languages() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	langs := make([]string, 0, len(c.messages))
+	for lang := range c.messages {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+func (c *Catalog) lookup(lang string, code int) (*template.Template, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	tmpl, ok := c.messages[lang][code]
+	return tmpl, ok
+}
+
+// Message returns the user-facing message of err in the language lang,
+// executed with the fields of err.
+//
+// lang is a language tag such as "de-AT", or a list of weighted tags in the
+// format of the Accept-Language HTTP header, such as "de-AT, de;q=0.9, en;q=0.5".
+// Each tag is matched against the languages of the Coder of err, if it is a
+// LocalizedCoder, and then of the Catalog, falling back to its parent tags
+// ("de-AT" falls back to "de"). A template that fails to execute, such as
+// one using a field that err does not have, is skipped. If no language
+// matches, the String of the Coder is returned, unless it has format verbs,
+// as PublicMessage does.
+// If err is nil, Message returns an empty string.
+func (c *Catalog) Message(err error, lang string) string {
+	if err == nil {
+		return ""
+	}
+	coder := coderOf(err)
+	fields := FieldsOf(err)
+	for _, tag := range languageTags(lang) {
+		if lc, ok := coder.(LocalizedCoder); ok {
+			if message, ok := lc.LocalizedString(tag); ok {
+				tmpl, perr := parseMessage(message)
+				if perr != nil {
+					return message
+				}
+				if msg, ok := executeMessage(tmpl, fields); ok {
+					return msg
+				}
+			}
+		}
+		if tmpl, ok := c.lookup(tag, coder.Code()); ok {
+			if msg, ok := executeMessage(tmpl, fields); ok {
+				return msg
+			}
+		}
+	}
+	return coderMessage(coder)
+}
+
+// Message returns the user-facing message of err in the language lang, using
+// the DefaultCatalog. See Catalog.Message.
+func Message(err error, lang string) string {
+	return DefaultCatalog.Message(err, lang)
+}
+
+func parseMessage(message string) (*template.Template, error) {
+	return template.New("").Option("missingkey=error").Parse(message)
+}
+
+// executeMessage executes tmpl with fields, and returns false if it fails,
+// for example because a field is missing.
+func executeMessage(tmpl *template.Template, fields Fields) (string, bool) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, fields); err != nil {
+		return "", false
+	}
+	return b.String(), true
+}
+
+// languageTags returns the canonical tags of lang ordered by preference,
+// each followed by its parent tags.
+func languageTags(lang string) []string {
+	type weighted struct {
+		tag    string
+		weight float64
+	}
+	var prefs []weighted
+	for _, part := range strings.Split(lang, ",") {
+		tag, weight := part, 1.0
+		if i := strings.Index(part, ";"); i >= 0 {
+			tag = part[:i]
+			if q := strings.TrimSpace(part[i+1:]); strings.HasPrefix(q, "q=") {
+				if w, err := strconv.ParseFloat(q[2:], 64); err == nil {
+					weight = w
+				}
+			}
+		}
+		tag = canonicalLanguage(tag)
+		if tag == "" || tag == "*" || weight <= 0 {
+			continue
+		}
+		prefs = append(prefs, weighted{tag, weight})
+	}
+	sort.SliceStable(prefs, func(i, j int) bool { return prefs[i].weight > prefs[j].weight })
+
+	var tags []string
+	seen := make(map[string]struct{})
+	for _, p := range prefs {
+		for tag := p.tag; tag != ""; tag = parentLanguage(tag) {
+			if _, ok := seen[tag]; !ok {
+				seen[tag] = struct{}{}
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// canonicalLanguage returns lang with "-" separators, a lower case language
+// and upper case two letter regions, for example "pt-BR" for "pt_br".
+func canonicalLanguage(lang string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"), "-")
+	for i, part := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(part)
+		case len(part) == 2:
+			parts[i] = strings.ToUpper(part)
+		case len(part) == 4:
+			parts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+		default:
+			parts[i] = strings.ToLower(part)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// parentLanguage returns the tag of lang without its last subtag, or an
+// empty string if lang has a single subtag.
+func parentLanguage(lang string) string {
+	i := strings.LastIndex(lang, "-")
+	if i < 0 {
+		return ""
+	}
+	return lang[:i]
+}
+
+func languageOfFile(name string) string {
+	base := path.Base(filepath.ToSlash(name))
+	base = strings.TrimSuffix(base, path.Ext(base))
+	if i := strings.LastIndex(base, "."); i >= 0 {
+		base = base[i+1:]
+	}
+	return base
+}
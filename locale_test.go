@@ -0,0 +1,191 @@
+package errors
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type localizedCoder struct {
+	defaultCoder
+	messages map[string]string
+}
+
+func (c localizedCoder) LocalizedString(lang string) (string, bool) {
+	msg, ok := c.messages[lang]
+	return msg, ok
+}
+
+func TestLanguageTags(t *testing.T) {
+	tests := []struct {
+		lang string
+		want []string
+	}{
+		{"", nil},
+		{"de", []string{"de"}},
+		{"de_at", []string{"de-AT", "de"}},
+		{"zh-hant-tw", []string{"zh-Hant-TW", "zh-Hant", "zh"}},
+		{"fr;q=0.5, de-AT, en;q=0.8", []string{"de-AT", "de", "en", "fr"}},
+		{"de-CH, de;q=0.9, *;q=0.1, it;q=0", []string{"de-CH", "de"}},
+	}
+	for _, tt := range tests {
+		if got := languageTags(tt.lang); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("languageTags(%q): want: %v, got: %v", tt.lang, tt.want, got)
+		}
+	}
+}
+
+func TestCatalogMessage(t *testing.T) {
+	codes := []Coder{
+		defaultCoder{code: 20010, status: 404, msg: "User not found"},
+		localizedCoder{
+			defaultCoder: defaultCoder{code: 20012, status: 403, msg: "Access denied"},
+			messages:     map[string]string{"de": "Zugriff auf {{.resource}} verweigert", "pt-BR": "Acesso negado"},
+		},
+	}
+	for _, v := range codes {
+		Register(v)
+	}
+	defer func() {
+		for _, v := range codes {
+			Unregister(v)
+		}
+	}()
+
+	c := NewCatalog()
+	if err := c.LoadFile("testdata/locales/messages.de.json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set("es", 20010, "Usuario {{.user}} no encontrado"); err != nil {
+		t.Fatal(err)
+	}
+
+	notFound := Wrap(WithFields(WithCode(io.EOF, 20010), Fields{"user": "alice"}), "query users")
+	denied := WithFields(WithCode(io.EOF, 20012), Fields{"resource": "invoices"})
+	tests := []struct {
+		err  error
+		lang string
+		want string
+	}{
+		{nil, "de", ""},
+		{notFound, "de", "Benutzer alice wurde nicht gefunden"},
+		{notFound, "de-AT", "Benutzer alice wurde nicht gefunden"},
+		{notFound, "es_MX", "Usuario alice no encontrado"},
+		{notFound, "it, es;q=0.5", "Usuario alice no encontrado"},
+		{notFound, "it", "User not found"},
+		{notFound, "", "User not found"},
+		{denied, "de-DE", "Zugriff auf invoices verweigert"},
+		{denied, "pt-br", "Acesso negado"},
+		{denied, "fr", "Access denied"},
+		{io.EOF, "de", "Internal server error"},
+	}
+	for _, tt := range tests {
+		if got := c.Message(tt.err, tt.lang); got != tt.want {
+			t.Errorf("Message(%v, %q): want: %q, got: %q", tt.err, tt.lang, tt.want, got)
+		}
+	}
+}
+
+func TestCatalogMessageMissingField(t *testing.T) {
+	mockCode := localizedCoder{
+		defaultCoder: defaultCoder{code: 20013, status: 404, msg: "User not found"},
+		messages:     map[string]string{"fr": "Utilisateur {{.user}} introuvable dans {{.tenant}}"},
+	}
+	Register(mockCode)
+	defer Unregister(mockCode)
+
+	c := NewCatalog()
+	if err := c.Set("de", 20013, "Benutzer {{.user}} {{.missing}}"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set("en", 20013, "User {{.user}} not found"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := WithFields(WithCode(io.EOF, 20013), Fields{"user": "bob"})
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{"de", "User not found"},
+		{"de, en;q=0.5", "User bob not found"},
+		{"fr, en;q=0.5", "User bob not found"},
+		{"fr", "User not found"},
+	}
+	for _, tt := range tests {
+		got := c.Message(err, tt.lang)
+		if got != tt.want {
+			t.Errorf("Message(%q): want: %q, got: %q", tt.lang, tt.want, got)
+		}
+		if strings.Contains(got, "no value") || strings.Contains(got, "{{") {
+			t.Errorf("Message(%q): unexpected %q", tt.lang, got)
+		}
+	}
+}
+
+func TestCatalogLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"locales/app.de.json": {Data: []byte(`{"20010": "Nicht gefunden"}`)},
+		"locales/app.ja.json": {Data: []byte(`{"20010": "見つかりません"}`)},
+		"locales/README.md":   {Data: []byte(`not a catalog`)},
+	}
+	c := NewCatalog()
+	if err := c.LoadFS(fsys, "locales/*.json"); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Languages(); !reflect.DeepEqual(got, []string{"de", "ja"}) {
+		t.Errorf("Languages: want: %v, got: %v", []string{"de", "ja"}, got)
+	}
+
+	if err := c.LoadFS(fsys, "locales/*.md"); err == nil {
+		t.Error("LoadFS: expected an error")
+	}
+}
+
+func TestCatalogLoadErrors(t *testing.T) {
+	c := NewCatalog()
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"abc": "message"}`, `invalid code "abc" for "de"`},
+		{`{"20010": "{{.user"}`, "parse message of code `20010` for \"de\""},
+		{`[]`, `decode messages for "de"`},
+	}
+	for _, tt := range tests {
+		err := c.Load("de", strings.NewReader(tt.input))
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("Load(%s): want: %q, got: %v", tt.input, tt.want, err)
+		}
+	}
+	if got := c.Languages(); len(got) != 0 {
+		t.Errorf("Languages: want: none, got: %v", got)
+	}
+	if err := c.Load("de", strings.NewReader(`{"20010": "ok", "20011": "{{.user"}`)); err == nil {
+		t.Error("Load: expected an error")
+	}
+	if _, ok := c.lookup("de", 20010); ok {
+		t.Error("Load: want: no message set, got one")
+	}
+	if err := c.LoadFile("testdata/locales/missing.de.json"); err == nil {
+		t.Error("LoadFile: expected an error")
+	}
+}
+
+func TestMessageDefaultCatalog(t *testing.T) {
+	mockCode := defaultCoder{code: 20010, status: 404, msg: "User not found"}
+	Register(mockCode)
+	defer Unregister(mockCode)
+
+	if err := DefaultCatalog.LoadFile("testdata/locales/messages.fr.json"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { DefaultCatalog = NewCatalog() }()
+
+	err := WithFields(WithCode(io.EOF, 20010), Fields{"user": "alice"})
+	if got, want := Message(err, "fr-CA"), "Utilisateur alice introuvable"; got != want {
+		t.Errorf("Message: want: %q, got: %q", want, got)
+	}
+}
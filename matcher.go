@@ -0,0 +1,93 @@
+package errors
+
+import "regexp"
+
+// MatchIs returns a Matcher that reports whether any error in the chain
+// matches target, as reported by Is.
+func MatchIs(target error) Matcher {
+	return func(err error) bool {
+		return walk(err, func(err error) bool {
+			return Is(err, target)
+		})
+	}
+}
+
+// MatchCode returns a Matcher that reports whether any error in the chain
+// has one of the given codes.
+func MatchCode(codes ...int) Matcher {
+	return func(err error) bool {
+		return walk(err, func(err error) bool {
+			v, ok := err.(icoder)
+			if !ok {
+				return false
+			}
+			for _, code := range codes {
+				if v.Code() == code {
+					return true
+				}
+			}
+			return false
+		})
+	}
+}
+
+// MatchMessageRegexp returns a Matcher that reports whether the message of
+// any error in the chain matches the regular expression expr.
+// It panics if expr cannot be parsed.
+func MatchMessageRegexp(expr string) Matcher {
+	re := regexp.MustCompile(expr)
+	return func(err error) bool {
+		return walk(err, func(err error) bool {
+			return re.MatchString(err.Error())
+		})
+	}
+}
+
+// MatchHTTPStatus returns a Matcher that reports whether the HTTP status of
+// the Coder of the error is within the range [from, to]. The Coder is parsed
+// as by ParseCoder from the first error of the chain that has a code or is
+// an Aggregate, or is the fallback Coder if there is none.
+func MatchHTTPStatus(from, to int) Matcher {
+	return func(err error) bool {
+		coder := fallbackCoder()
+		walk(err, func(err error) bool {
+			_, isCoder := err.(icoder)
+			_, isAggregate := err.(Aggregate)
+			if !isCoder && !isAggregate {
+				return false
+			}
+			if c, ok := parseCoder(err, nil); ok {
+				coder = c
+			}
+			return true
+		})
+		status := coder.HTTPStatus()
+		return status >= from && status <= to
+	}
+}
+
+// And returns a Matcher that reports whether all fns match.
+func And(fns ...Matcher) Matcher {
+	return func(err error) bool {
+		for _, fn := range fns {
+			if !fn(err) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or returns a Matcher that reports whether any of fns matches.
+func Or(fns ...Matcher) Matcher {
+	return func(err error) bool {
+		return matchesError(err, fns...)
+	}
+}
+
+// Not returns a Matcher that reports whether fn does not match.
+func Not(fn Matcher) Matcher {
+	return func(err error) bool {
+		return !fn(err)
+	}
+}
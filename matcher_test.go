@@ -0,0 +1,146 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// causeOnly is a wrapper that only implements causer.
+type causeOnly struct{ cause error }
+
+func (c causeOnly) Error() string { return "cause only: " + c.cause.Error() }
+func (c causeOnly) Cause() error  { return c.cause }
+
+func TestMatchIs(t *testing.T) {
+	runs := []struct {
+		expected bool
+		err      error
+	}{
+		{true, io.EOF},
+		{true, Wrap(io.EOF, "read")},
+		{true, fmt.Errorf("read: %w", io.EOF)},
+		{true, causeOnly{io.EOF}},
+		{true, Wrap(causeOnly{fmt.Errorf("read: %w", io.EOF)}, "outer")},
+		{false, errors.New("EOF")},
+		{false, io.ErrUnexpectedEOF},
+	}
+	for i, r := range runs {
+		if got := MatchIs(io.EOF)(r.err); got != r.expected {
+			t.Errorf("%d: MatchIs: want: %v, got: %v", i, r.expected, got)
+		}
+	}
+}
+
+func TestMatchCode(t *testing.T) {
+	runs := []struct {
+		expected bool
+		codes    []int
+		err      error
+	}{
+		{true, []int{20001}, WithCode(io.EOF, 20001)},
+		{true, []int{20002, 20001}, Wrap(WithCode(io.EOF, 20001), "read")},
+		{true, []int{20001}, fmt.Errorf("read: %w", WithCode(io.EOF, 20001))},
+		{true, []int{20001}, causeOnly{WithCode(io.EOF, 20001)}},
+		{false, []int{20002}, WithCode(io.EOF, 20001)},
+		{false, nil, WithCode(io.EOF, 20001)},
+		{false, []int{20001}, io.EOF},
+	}
+	for i, r := range runs {
+		if got := MatchCode(r.codes...)(r.err); got != r.expected {
+			t.Errorf("%d: MatchCode: want: %v, got: %v", i, r.expected, got)
+		}
+	}
+}
+
+func TestMatchMessageRegexp(t *testing.T) {
+	runs := []struct {
+		expected bool
+		expr     string
+		err      error
+	}{
+		{true, "^EOF$", io.EOF},
+		{true, "^EOF$", Wrap(io.EOF, "read")},
+		{true, "^read: EOF$", fmt.Errorf("read: %w", io.EOF)},
+		{true, "refused", causeOnly{errors.New("connection refused")}},
+		{false, "^read$", Wrap(io.EOF, "read")},
+	}
+	for i, r := range runs {
+		if got := MatchMessageRegexp(r.expr)(r.err); got != r.expected {
+			t.Errorf("%d: MatchMessageRegexp: want: %v, got: %v", i, r.expected, got)
+		}
+	}
+}
+
+func TestMatchHTTPStatus(t *testing.T) {
+	codes := []Coder{
+		defaultCoder{code: 20001, status: 404, msg: "not found"},
+		defaultCoder{code: 20002, status: 503, msg: "unavailable"},
+	}
+	for _, v := range codes {
+		Register(v)
+	}
+	defer func() {
+		for _, v := range codes {
+			Unregister(v)
+		}
+	}()
+
+	runs := []struct {
+		expected bool
+		from, to int
+		err      error
+	}{
+		{true, 400, 499, WithCode(io.EOF, 20001)},
+		{true, 400, 499, fmt.Errorf("read: %w", WithCode(io.EOF, 20001))},
+		{true, 500, 599, Wrap(WithCode(io.EOF, 20002), "read")},
+		{true, 500, 599, io.EOF},
+		{true, 500, 599, WithCode(io.EOF, 99999)},
+		{false, 400, 499, WithCode(io.EOF, 20002)},
+	}
+	for i, r := range runs {
+		if got := MatchHTTPStatus(r.from, r.to)(r.err); got != r.expected {
+			t.Errorf("%d: MatchHTTPStatus: want: %v, got: %v", i, r.expected, got)
+		}
+	}
+}
+
+func TestMatcherCombinators(t *testing.T) {
+	isEOF := MatchIs(io.EOF)
+	hasCode := MatchCode(20001)
+	runs := []struct {
+		name     string
+		expected bool
+		fn       Matcher
+		err      error
+	}{
+		{"and", true, And(isEOF, hasCode), WithCode(io.EOF, 20001)},
+		{"and partial", false, And(isEOF, hasCode), io.EOF},
+		{"and empty", true, And(), io.EOF},
+		{"or", true, Or(isEOF, hasCode), io.EOF},
+		{"or none", false, Or(isEOF, hasCode), io.ErrClosedPipe},
+		{"or empty", false, Or(), io.EOF},
+		{"not", true, Not(isEOF), io.ErrClosedPipe},
+		{"not match", false, Not(isEOF), io.EOF},
+		{"nested", true, And(Not(hasCode), Or(isEOF, MatchIs(io.ErrClosedPipe))), io.ErrClosedPipe},
+	}
+	for _, r := range runs {
+		if got := r.fn(r.err); got != r.expected {
+			t.Errorf("%s: want: %v, got: %v", r.name, r.expected, got)
+		}
+	}
+}
+
+func TestFilterOutWithMatchers(t *testing.T) {
+	agg := NewAggregate([]error{
+		io.EOF,
+		Wrap(io.EOF, "read"),
+		WithCode(errors.New("a"), 20001),
+		errors.New("b"),
+	})
+	got := FilterOut(agg, MatchIs(io.EOF), MatchCode(20001))
+	if got == nil || got.Error() != "b" {
+		t.Errorf("FilterOut: want: %q, got: %v", "b", got)
+	}
+}
@@ -0,0 +1,165 @@
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NamespacedCoder is a Coder declaring the namespace it belongs to, such as
+// the name of the team or the service owning it. Register panics if its code
+// is outside the ranges reserved by its namespace with ReserveRange.
+type NamespacedCoder interface {
+	Coder
+
+	// Namespace returns the namespace of the coder, "" if it has none.
+	Namespace() string
+}
+
+// CodeRange is a range of codes reserved by a namespace, from Min to Max
+// inclusive.
+type CodeRange struct {
+	Namespace string
+	Min       int
+	Max       int
+}
+
+// Contains reports whether code is in r.
+func (r CodeRange) Contains(code int) bool { return r.Min <= code && code <= r.Max }
+
+func (r CodeRange) String() string {
+	if r.Namespace == "" {
+		return fmt.Sprintf("[%d, %d]", r.Min, r.Max)
+	}
+	return fmt.Sprintf("%s [%d, %d]", r.Namespace, r.Min, r.Max)
+}
+
+// _ranges reserved ranges, in the order of ReserveRange.
+var _ranges []CodeRange
+
+// ReserveRange reserves the codes from min to max inclusive for the namespace
+// ns. A namespace may reserve several ranges. Overlapping ranges are allowed,
+// see RangeReport.
+// ReserveRange panics if ns is empty, if min is greater than max, or if the
+// range contains the Unknown Code.
+func ReserveRange(ns string, min, max int) {
+	if ns == "" {
+		panic("namespace must not be empty")
+	}
+	if min > max {
+		panic(fmt.Sprintf("invalid range [%d, %d] of namespace `%s`", min, max, ns))
+	}
+	r := CodeRange{Namespace: ns, Min: min, Max: max}
+	if r.Contains(unknownCode.Code()) {
+		panic(fmt.Sprintf("code `%d` is reserved by `github.com/shipengqi/errors` as Unknown Code", unknownCode.Code()))
+	}
+	mux.Lock()
+	defer mux.Unlock()
+
+	_ranges = append(_ranges, r)
+}
+
+// checkNamespace returns an *ErrCodeOutOfRange if code is a NamespacedCoder
+// whose code is outside the ranges reserved by its namespace. It must be
+// called with mux held.
+func checkNamespace(code Coder) error {
+	v, ok := code.(NamespacedCoder)
+	if !ok || v.Namespace() == "" {
+		return nil
+	}
+	for _, r := range _ranges {
+		if r.Namespace == v.Namespace() && r.Contains(code.Code()) {
+			return nil
+		}
+	}
+	return &ErrCodeOutOfRange{Coder: v, Namespace: v.Namespace()}
+}
+
+// NamespaceOf returns the namespace owning code: the namespace of its
+// registered Coder if it is a NamespacedCoder, or else the namespace of the
+// first reserved range containing it. NamespaceOf returns "" if no namespace
+// owns code.
+func NamespaceOf(code int) string {
+	mux.RLock()
+	defer mux.RUnlock()
+
+	if v, ok := _codes[code].(NamespacedCoder); ok && v.Namespace() != "" {
+		return v.Namespace()
+	}
+	for _, r := range _ranges {
+		if r.Contains(code) {
+			return r.Namespace
+		}
+	}
+	return ""
+}
+
+// RangeOverlap is a pair of reserved ranges that have codes in common.
+type RangeOverlap struct {
+	A, B CodeRange
+}
+
+// RangeReport describes the reserved ranges, meant to be logged at startup.
+type RangeReport struct {
+	// Ranges are the reserved ranges, sorted by Min then Max.
+	Ranges []CodeRange
+
+	// Gaps are the ranges of codes that are between two reserved ranges but
+	// reserved by no namespace. Their Namespace is empty.
+	Gaps []CodeRange
+
+	// Overlaps are the pairs of reserved ranges that have codes in common.
+	Overlaps []RangeOverlap
+}
+
+// ReportRanges returns the RangeReport of the reserved ranges.
+func ReportRanges() RangeReport {
+	mux.RLock()
+	ranges := make([]CodeRange, len(_ranges))
+	copy(ranges, _ranges)
+	mux.RUnlock()
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].Min != ranges[j].Min {
+			return ranges[i].Min < ranges[j].Min
+		}
+		return ranges[i].Max < ranges[j].Max
+	})
+	report := RangeReport{Ranges: ranges}
+	for i, a := range ranges {
+		for _, b := range ranges[i+1:] {
+			if b.Min > a.Max {
+				break
+			}
+			report.Overlaps = append(report.Overlaps, RangeOverlap{A: a, B: b})
+		}
+	}
+	for i := 0; i < len(ranges); i++ {
+		end := ranges[i].Max
+		for i+1 < len(ranges) && ranges[i+1].Min <= end+1 {
+			i++
+			if ranges[i].Max > end {
+				end = ranges[i].Max
+			}
+		}
+		if i+1 < len(ranges) {
+			report.Gaps = append(report.Gaps, CodeRange{Min: end + 1, Max: ranges[i+1].Min - 1})
+		}
+	}
+	return report
+}
+
+// String formats the report with a line per range, gap and overlap.
+func (r RangeReport) String() string {
+	var b strings.Builder
+	for _, x := range r.Ranges {
+		fmt.Fprintf(&b, "range: %s\n", x)
+	}
+	for _, x := range r.Gaps {
+		fmt.Fprintf(&b, "gap: %s\n", x)
+	}
+	for _, x := range r.Overlaps {
+		fmt.Fprintf(&b, "overlap: %s and %s\n", x.A, x.B)
+	}
+	return b.String()
+}
@@ -0,0 +1,156 @@
+package errors
+
+import (
+	"reflect"
+	"testing"
+)
+
+type namespacedCoder struct {
+	defaultCoder
+	ns string
+}
+
+func (c namespacedCoder) Namespace() string { return c.ns }
+
+func resetRanges() func() {
+	saved := _ranges
+	_ranges = nil
+	return func() { _ranges = saved }
+}
+
+func TestReserveRange(t *testing.T) {
+	defer resetRanges()()
+
+	ReserveRange("billing", 47000, 47099)
+	ReserveRange("billing", 47200, 47299)
+	ReserveRange("users", 47100, 47199)
+
+	inRange := namespacedCoder{defaultCoder{code: 47201, msg: "payment declined"}, "billing"}
+	Register(inRange)
+	defer Unregister(inRange)
+
+	plain := defaultCoder{code: 47150, msg: "plain"}
+	Register(plain)
+	defer Unregister(plain)
+
+	tests := []struct {
+		code int
+		want string
+	}{
+		{47000, "billing"},
+		{47099, "billing"},
+		{47100, "users"},
+		{47150, "users"},
+		{47201, "billing"},
+		{47300, ""},
+		{1, ""},
+	}
+	for _, tt := range tests {
+		if got := NamespaceOf(tt.code); got != tt.want {
+			t.Errorf("NamespaceOf(%d): want: %q, got: %q", tt.code, tt.want, got)
+		}
+	}
+
+	t.Run("defined", func(t *testing.T) {
+		s := Define(47001, "invoice not found", DefineNamespace("billing"))
+		defer Unregister(s)
+		if s.Namespace() != "billing" {
+			t.Errorf("namespace: want: %s, got: %s", "billing", s.Namespace())
+		}
+	})
+}
+
+func TestRegisterOutsideNamespace(t *testing.T) {
+	defer resetRanges()()
+
+	ReserveRange("billing", 47000, 47099)
+	ReserveRange("users", 47100, 47199)
+
+	tests := []struct {
+		coder Coder
+		want  string
+	}{
+		{namespacedCoder{defaultCoder{code: 47100}, "billing"},
+			"code `47100` is outside the ranges reserved by namespace `billing`"},
+		{namespacedCoder{defaultCoder{code: 47000}, "orders"},
+			"code `47000` is outside the ranges reserved by namespace `orders`"},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if r := recover(); r != tt.want {
+					t.Errorf("recover: want: %v, got: %v", tt.want, r)
+				}
+			}()
+			Register(tt.coder)
+			Unregister(tt.coder)
+		}()
+	}
+}
+
+func TestReserveRangePanic(t *testing.T) {
+	defer resetRanges()()
+
+	tests := []struct {
+		ns       string
+		min, max int
+		want     string
+	}{
+		{"", 47000, 47099, "namespace must not be empty"},
+		{"billing", 47099, 47000, "invalid range [47099, 47000] of namespace `billing`"},
+		{"billing", 0, 10, "code `1` is reserved by `github.com/shipengqi/errors` as Unknown Code"},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if r := recover(); r != tt.want {
+					t.Errorf("recover: want: %v, got: %v", tt.want, r)
+				}
+			}()
+			ReserveRange(tt.ns, tt.min, tt.max)
+		}()
+	}
+}
+
+func TestReportRanges(t *testing.T) {
+	defer resetRanges()()
+
+	ReserveRange("users", 20000, 20999)
+	ReserveRange("billing", 30000, 30999)
+	ReserveRange("orders", 30500, 31499)
+	ReserveRange("search", 31500, 31999)
+	ReserveRange("ads", 40000, 40099)
+
+	got := ReportRanges()
+	want := RangeReport{
+		Ranges: []CodeRange{
+			{"users", 20000, 20999},
+			{"billing", 30000, 30999},
+			{"orders", 30500, 31499},
+			{"search", 31500, 31999},
+			{"ads", 40000, 40099},
+		},
+		Gaps: []CodeRange{
+			{"", 21000, 29999},
+			{"", 32000, 39999},
+		},
+		Overlaps: []RangeOverlap{
+			{CodeRange{"billing", 30000, 30999}, CodeRange{"orders", 30500, 31499}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReportRanges: want: %+v, got: %+v", want, got)
+	}
+
+	wantString := "range: users [20000, 20999]\n" +
+		"range: billing [30000, 30999]\n" +
+		"range: orders [30500, 31499]\n" +
+		"range: search [31500, 31999]\n" +
+		"range: ads [40000, 40099]\n" +
+		"gap: [21000, 29999]\n" +
+		"gap: [32000, 39999]\n" +
+		"overlap: billing [30000, 30999] and orders [30500, 31499]\n"
+	if got.String() != wantString {
+		t.Errorf("String: want: %q, got: %q", wantString, got.String())
+	}
+}
@@ -0,0 +1,160 @@
+package errors
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// NormalizeOptions are the options of Normalize.
+type NormalizeOptions struct {
+	// StripLines removes the line numbers of the frames.
+	StripLines bool
+
+	// KeepRuntime keeps the frames of the runtime package, which are removed
+	// by default.
+	KeepRuntime bool
+}
+
+// frameFileRegexp matches the second line of a frame in the %+v output.
+var frameFileRegexp = regexp.MustCompile(`^\t(.+):(\d+)$`)
+
+// Normalize returns the %+v output of err in a form that does not depend on
+// the machine it runs on, meant for golden files.
+//
+// The absolute paths of the frames are replaced with the import path of
+// their package followed by the file name, the same as the paths recorded
+// by a build with -trimpath: the module path and the path relative to the
+// module root for the files of a module, the path relative to GOROOT/src for
+// the files of the standard library. The module of a file is found from the
+// go.mod file of its directory or of a parent one. When the sources are not
+// available, the package path is taken from the function name instead.
+func Normalize(err error, opts NormalizeOptions) string {
+	if err == nil {
+		return ""
+	}
+	return normalizeText(fmt.Sprintf("%+v", err), opts)
+}
+
+// normalizeText normalizes the frames found in the %+v output s.
+func normalizeText(s string, opts NormalizeOptions) string {
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); i++ {
+		if i+1 == len(lines) {
+			out = append(out, lines[i])
+			break
+		}
+		m := frameFileRegexp.FindStringSubmatch(lines[i+1])
+		if m == nil {
+			out = append(out, lines[i])
+			continue
+		}
+		name, file, line := lines[i], m[1], m[2]
+		i++
+		if !opts.KeepRuntime && strings.HasPrefix(name, "runtime.") {
+			continue
+		}
+		file = normalizePath(file, name)
+		if opts.StripLines {
+			out = append(out, name, "\t"+file)
+		} else {
+			out = append(out, name, "\t"+file+":"+line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// normalizePath returns the path of file, the source file of the function
+// name, relative to the import path of its package.
+func normalizePath(file, name string) string {
+	file = filepath.ToSlash(file)
+	if !path.IsAbs(file) && !filepath.IsAbs(filepath.FromSlash(file)) {
+		// built with -trimpath
+		return file
+	}
+	if mod := findModule(path.Dir(file)); mod != nil {
+		rel := strings.TrimPrefix(file, mod.root+"/")
+		if mod.path == "std" {
+			return rel
+		}
+		return mod.path + "/" + rel
+	}
+	if name == unknown {
+		return file
+	}
+	pkg := name
+	slash := strings.LastIndex(pkg, "/")
+	if i := strings.Index(pkg[slash+1:], "."); i >= 0 {
+		pkg = pkg[:slash+1+i]
+	}
+	return pkg + "/" + path.Base(file)
+}
+
+// module is a module found on disk.
+type module struct {
+	root string // directory of the go.mod file
+	path string // module path
+}
+
+var (
+	modulesMu sync.Mutex
+	// _modules caches the modules of the directories, nil if there is none.
+	_modules = make(map[string]*module)
+)
+
+// findModule returns the module containing the directory dir, if any.
+func findModule(dir string) *module {
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+
+	var visited []string
+	var mod *module
+	for {
+		if m, ok := _modules[dir]; ok {
+			mod = m
+			break
+		}
+		visited = append(visited, dir)
+		if p, ok := modulePath(dir + "/go.mod"); ok {
+			mod = &module{root: dir, path: p}
+			break
+		}
+		parent := path.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	for _, d := range visited {
+		_modules[d] = mod
+	}
+	return mod
+}
+
+// modulePath returns the module path declared by the go.mod file gomod.
+func modulePath(gomod string) (string, bool) {
+	data, err := os.ReadFile(filepath.FromSlash(gomod))
+	if err != nil {
+		return "", false
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 2 || fields[0] != "module" {
+			continue
+		}
+		if p, err := strconv.Unquote(fields[1]); err == nil {
+			return p, true
+		}
+		return fields[1], true
+	}
+	return "", false
+}
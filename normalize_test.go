@@ -0,0 +1,94 @@
+package errors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func normalizeFixture() error {
+	return Wrap(New("no rows"), "find user")
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		opts NormalizeOptions
+	}{
+		{"wrapped", normalizeFixture(), NormalizeOptions{StripLines: true}},
+		{"foreign", Wrap(fmt.Errorf("EOF"), "read"), NormalizeOptions{StripLines: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Normalize(tt.err, tt.opts)
+			file := filepath.Join("testdata", "normalize", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(file, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("%s: want:\n%s\ngot:\n%s", file, want, got)
+			}
+		})
+	}
+}
+
+func TestNormalizeLines(t *testing.T) {
+	got := Normalize(New("boom"), NormalizeOptions{})
+	want := "^boom\ngithub.com/shipengqi/errors.TestNormalizeLines\n\tgithub.com/shipengqi/errors/normalize_test.go:\\d+\n"
+	if !regexp.MustCompile(want).MatchString(got) {
+		t.Errorf("want match of %q, got:\n%s", want, got)
+	}
+	if Normalize(nil, NormalizeOptions{}) != "" {
+		t.Errorf("want empty output for nil")
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		in   string
+		opts NormalizeOptions
+		want string
+	}{
+		{
+			"boom\ngithub.com/a/b.F\n\t/nonexistent/src/b/b.go:12",
+			NormalizeOptions{},
+			"boom\ngithub.com/a/b.F\n\tgithub.com/a/b/b.go:12",
+		},
+		{
+			"boom\ngithub.com/a/b.(*T).M\n\t/nonexistent/src/b/b.go:12",
+			NormalizeOptions{StripLines: true},
+			"boom\ngithub.com/a/b.(*T).M\n\tgithub.com/a/b/b.go",
+		},
+		{
+			"boom\ngithub.com/a/b.F\n\tgithub.com/a/b/b.go:12\nruntime.goexit\n\t/nonexistent/runtime/asm_amd64.s:1700",
+			NormalizeOptions{},
+			"boom\ngithub.com/a/b.F\n\tgithub.com/a/b/b.go:12",
+		},
+		{
+			"boom\nruntime.goexit\n\t/nonexistent/runtime/asm_amd64.s:1700",
+			NormalizeOptions{KeepRuntime: true},
+			"boom\nruntime.goexit\n\truntime/asm_amd64.s:1700",
+		},
+		{
+			"no frames\n\tat all",
+			NormalizeOptions{},
+			"no frames\n\tat all",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeText(tt.in, tt.opts); got != tt.want {
+			t.Errorf("normalizeText(%q): want:\n%s\ngot:\n%s", tt.in, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package errors
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// IDGenerator returns a unique error instance ID.
+type IDGenerator func() string
+
+// CaptureOptions selects what is recorded, in addition to the stack trace,
+// by New, Errorf, and by the wrappers recording a stack trace (such as Wrap
+// and WithStack) when their cause has nothing recorded yet.
+type CaptureOptions struct {
+	// Timestamp records the creation time, see Timestamp.
+	Timestamp bool
+
+	// GoroutineID records the ID of the goroutine creating the error. It is
+	// meant for debugging, as reading it requires a call to runtime.Stack.
+	GoroutineID bool
+
+	// Component is a label recorded with the error, such as the name of the
+	// service or of the subsystem creating it. It can be set for a single
+	// error with WithComponent or ContextWithComponent, see Component.
+	Component string
+}
+
+// capture is what is recorded when an error is created.
+type capture struct {
+	idGenerator IDGenerator
+	options     CaptureOptions
+}
+
+func (c *capture) enabled() bool {
+	return c != nil && (c.idGenerator != nil || c.options != CaptureOptions{})
+}
+
+var (
+	_capture  atomic.Value
+	captureMu sync.Mutex
+)
+
+func loadCapture() *capture {
+	c, _ := _capture.Load().(*capture)
+	return c
+}
+
+// updateCapture replaces the capture with a copy modified by fn.
+func updateCapture(fn func(c *capture)) {
+	captureMu.Lock()
+	defer captureMu.Unlock()
+
+	c := &capture{}
+	if old := loadCapture(); old != nil {
+		*c = *old
+	}
+	fn(c)
+	_capture.Store(c)
+}
+
+// SetIDGenerator sets the IDGenerator used to give an instance ID to the
+// errors created by New, Errorf, and by the wrappers recording a stack trace
+// (such as Wrap and WithStack) when their cause has none yet. The ID is then
+// shared by every error wrapping it, see ID.
+// A nil IDGenerator disables instance IDs, which is the default.
+func SetIDGenerator(g IDGenerator) {
+	updateCapture(func(c *capture) { c.idGenerator = g })
+}
+
+// SetCaptureOptions sets what is recorded when an error is created. The zero
+// CaptureOptions, which is the default, records nothing and costs nothing.
+func SetCaptureOptions(opts CaptureOptions) {
+	updateCapture(func(c *capture) { c.options = opts })
+}
+
+// RandomID is an IDGenerator returning 128 random bits as a hex string.
+func RandomID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// origin records what identifies an error at the point it is created.
+type origin struct {
+	id        string
+	time      time.Time
+	goroutine uint64
+	component string
+}
+
+// newOrigin returns the origin of an error wrapping cause, or nil if there
+// is nothing to record or if cause already has an origin.
+func newOrigin(cause error) *origin {
+	c := loadCapture()
+	if !c.enabled() {
+		return nil
+	}
+	if cause != nil && originOf(cause) != nil {
+		return nil
+	}
+	o := &origin{}
+	if cause == nil || Component(cause) == "" {
+		o.component = c.options.Component
+	}
+	if c.idGenerator != nil {
+		o.id = c.idGenerator()
+	}
+	if c.options.Timestamp {
+		o.time = time.Now()
+	}
+	if c.options.GoroutineID {
+		o.goroutine = goroutineID()
+	}
+	return o
+}
+
+// originOf returns the first origin recorded in err's chain, if any.
+func originOf(err error) *origin {
+	var o *origin
+	walk(err, func(err error) bool {
+		if v, ok := err.(interface{ recorded() *origin }); ok {
+			o = v.recorded()
+		}
+		return o != nil
+	})
+	return o
+}
+
+func (o *origin) recorded() *origin { return o }
+
+// ID returns the instance ID of the error, if any.
+func (o *origin) ID() string {
+	if o == nil {
+		return ""
+	}
+	return o.id
+}
+
+// Timestamp returns the creation time of the error, if recorded.
+func (o *origin) Timestamp() time.Time {
+	if o == nil {
+		return time.Time{}
+	}
+	return o.time
+}
+
+// Component returns the component recorded with the error, if any.
+func (o *origin) Component() string {
+	if o == nil {
+		return ""
+	}
+	return o.component
+}
+
+// format writes the origin for the %+v verb.
+func (o *origin) format(s io.Writer) {
+	if o == nil {
+		return
+	}
+	if o.id != "" {
+		_, _ = fmt.Fprintf(s, "\nid: %s", o.id)
+	}
+	if !o.time.IsZero() {
+		_, _ = fmt.Fprintf(s, "\ntime: %s", o.time.Format(time.RFC3339Nano))
+	}
+	if o.goroutine != 0 {
+		_, _ = fmt.Fprintf(s, "\ngoroutine: %d", o.goroutine)
+	}
+	if o.component != "" {
+		_, _ = fmt.Fprintf(s, "\ncomponent: %s", o.component)
+	}
+}
+
+// goroutineID returns the ID of the current goroutine, parsed from the
+// header of its stack trace: "goroutine 18 [running]:".
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	fields := strings.Fields(string(buf[:n]))
+	if len(fields) < 2 {
+		return 0
+	}
+	id, _ := strconv.ParseUint(fields[1], 10, 64)
+	return id
+}
+
+// Timestamp returns the creation time of err, that is the first non-zero
+// time found in err's chain of an error that implements
+//
+//	type timestamper interface {
+//	        Timestamp() time.Time
+//	}
+//
+// See SetCaptureOptions. Timestamp returns the zero time if there is none.
+func Timestamp(err error) time.Time {
+	var t time.Time
+	walk(err, func(err error) bool {
+		if v, ok := err.(interface{ Timestamp() time.Time }); ok {
+			t = v.Timestamp()
+		}
+		return !t.IsZero()
+	})
+	return t
+}
+
+// ID returns the instance ID of err, that is the first non-empty ID found in
+// err's chain of an error that implements
+//
+//	type identifier interface {
+//	        ID() string
+//	}
+//
+// See SetIDGenerator. ID returns an empty string if there is none.
+func ID(err error) string {
+	var id string
+	walk(err, func(err error) bool {
+		if v, ok := err.(interface{ ID() string }); ok {
+			id = v.ID()
+		}
+		return id != ""
+	})
+	return id
+}
+
+// fingerprintFrames is the number of frames of the stack trace that are
+// part of a fingerprint.
+const fingerprintFrames = 3
+
+var dynamicValueRegexps = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`"[^"]*"|'[^']*'`), `"?"`},
+	{regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`), "<uuid>"},
+	{regexp.MustCompile(`\b0x[0-9a-fA-F]+\b|\b[0-9a-fA-F]*[0-9][0-9a-fA-F]*[a-fA-F][0-9a-fA-F]*\b`), "<hex>"},
+	{regexp.MustCompile(`\d+(\.\d+)?`), "<n>"},
+}
+
+// Fingerprint returns a stable hash of err that is the same for all the
+// occurrences of the same bug, so that they can be grouped together.
+//
+// The hash covers the code of the Coder of err, the message template of the
+// root cause of err, and the function and file names of the top frames of
+// the stack trace recorded closest to the root cause, skipping the frames
+// outside of the project, see SetInProjectPrefixes. The message template is
+// the format given to Errorf; for other errors the message is used with the
+// numbers, hexadecimal values, UUIDs and quoted strings it contains replaced
+// by placeholders.
+// If err is nil, Fingerprint returns an empty string.
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+	var (
+		root  error
+		stack StackTrace
+	)
+	walk(err, func(err error) bool {
+		root = err
+		if v, ok := err.(interface{ StackTrace() StackTrace }); ok {
+			stack = v.StackTrace()
+		}
+		return false
+	})
+
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%d\n%s\n", coderOf(err).Code(), messageTemplate(root))
+	prefixes := inProjectPrefixes()
+	n := 0
+	for _, f := range stack {
+		if n == fingerprintFrames {
+			break
+		}
+		name := f.name()
+		if !isInProjectFunc(name, prefixes) {
+			continue
+		}
+		_, _ = fmt.Fprintf(h, "%s %s\n", name, path.Base(f.file()))
+		n++
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// messageTemplate returns the message of err without its dynamic values.
+func messageTemplate(err error) string {
+	if f, ok := err.(*fundamental); ok && f.format != "" {
+		return f.format
+	}
+	msg := err.Error()
+	for _, v := range dynamicValueRegexps {
+		msg = v.re.ReplaceAllString(msg, v.repl)
+	}
+	return msg
+}
+
+// _inProject holds the import path prefixes of the in-project packages, see
+// SetInProjectPrefixes.
+var _inProject struct {
+	sync.RWMutex
+	once     sync.Once
+	prefixes []string
+}
+
+// SetInProjectPrefixes sets the import path prefixes of the packages of the
+// project, whose frames are the ones hashed by Fingerprint. A prefix matches
+// the package with that import path and the packages below it.
+// By default, the path of the main module is used, as read from the build
+// information of the binary.
+func SetInProjectPrefixes(prefixes ...string) {
+	_inProject.once.Do(func() {})
+	_inProject.Lock()
+	defer _inProject.Unlock()
+	_inProject.prefixes = append([]string(nil), prefixes...)
+}
+
+// inProjectPrefixes returns the prefixes set by SetInProjectPrefixes, or the
+// path of the main module if none was set.
+func inProjectPrefixes() []string {
+	_inProject.once.Do(func() {
+		if info, ok := debug.ReadBuildInfo(); ok && info.Main.Path != "" {
+			_inProject.prefixes = []string{info.Main.Path}
+		}
+	})
+	_inProject.RLock()
+	defer _inProject.RUnlock()
+	return _inProject.prefixes
+}
+
+// isInProjectFunc reports whether the function name belongs to a package of
+// the project: the main package, or a package matching one of prefixes. With
+// no prefixes, any package outside of the standard library, whose import
+// paths have no dot in their first element, is part of the project.
+func isInProjectFunc(name string, prefixes []string) bool {
+	pkg := funcPackage(name)
+	if pkg == "main" {
+		return true
+	}
+	if len(prefixes) == 0 {
+		first := pkg
+		if i := strings.Index(first, "/"); i >= 0 {
+			first = first[:i]
+		}
+		return strings.Contains(first, ".")
+	}
+	for _, prefix := range prefixes {
+		if pkg == prefix || strings.HasPrefix(pkg, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// funcPackage returns the import path of the package of the function name.
+func funcPackage(name string) string {
+	slash := strings.LastIndex(name, "/")
+	if i := strings.Index(name[slash+1:], "."); i >= 0 {
+		return name[:slash+1+i]
+	}
+	return name
+}
@@ -0,0 +1,276 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sequentialIDs returns an IDGenerator returning "id-1", "id-2", ...
+func sequentialIDs() IDGenerator {
+	var n int
+	return func() string {
+		n++
+		return "id-" + strconv.Itoa(n)
+	}
+}
+
+func TestID(t *testing.T) {
+	if got := ID(New("error")); got != "" {
+		t.Errorf("ID: want: empty when disabled, got: %q", got)
+	}
+
+	SetIDGenerator(sequentialIDs())
+	defer SetIDGenerator(nil)
+
+	root := New("error")
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{io.EOF, ""},
+		{root, "id-1"},
+		{Wrap(root, "wrapped"), "id-1"},
+		{WithStack(Wrapf(root, "wrapped")), "id-1"},
+		{fmt.Errorf("wrapped: %w", root), "id-1"},
+		{Errorf("error"), "id-2"},
+		{Wrap(io.EOF, "read"), "id-3"},
+		{WithMessage(io.EOF, "read"), ""},
+		{WrapCode(WithMessage(io.EOF, "read"), 20001), "id-4"},
+	}
+	for i, tt := range tests {
+		if got := ID(tt.err); got != tt.want {
+			t.Errorf("%d: ID: want: %q, got: %q", i, tt.want, got)
+		}
+	}
+}
+
+func TestIDFormat(t *testing.T) {
+	SetIDGenerator(func() string { return "0123456789abcdef" })
+	defer SetIDGenerator(nil)
+
+	got := fmt.Sprintf("%+v", New("error"))
+	want := "^error\nid: 0123456789abcdef\ngithub.com/shipengqi/errors.TestIDFormat\n"
+	if !regexp.MustCompile(want).MatchString(got) {
+		t.Errorf("Sprintf(%%+v):\n got: %q\nwant: %q", got, want)
+	}
+
+	b, err := MarshalError(Wrap(io.EOF, "read"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(b), `{"id":"0123456789abcdef","message":"read: EOF"`) {
+		t.Errorf("MarshalError: unexpected %s", b)
+	}
+}
+
+func TestRandomID(t *testing.T) {
+	a, b := RandomID(), RandomID()
+	if !regexp.MustCompile("^[0-9a-f]{32}$").MatchString(a) {
+		t.Errorf("RandomID: unexpected %q", a)
+	}
+	if a == b {
+		t.Errorf("RandomID: expected distinct IDs, got %q twice", a)
+	}
+}
+
+func newUserError(id int) error {
+	return Errorf("user %d not found", id)
+}
+
+func newOrderError(id int) error {
+	return New("order " + strconv.Itoa(id) + " not found")
+}
+
+func TestFingerprint(t *testing.T) {
+	mockCode := defaultCoder{code: 20001, status: 404, msg: "not found"}
+	Register(mockCode)
+	defer Unregister(mockCode)
+
+	if got := Fingerprint(nil); got != "" {
+		t.Errorf("Fingerprint(nil): want: empty, got: %q", got)
+	}
+
+	same := [][]error{
+		{newUserError(1), newUserError(2), Wrap(newUserError(3), "lookup")},
+		{newOrderError(1), newOrderError(22)},
+		{
+			WithMessage(fmt.Errorf("read 0x1f at %q: %w", "/tmp/a", io.EOF), "dead1"),
+			WithMessage(fmt.Errorf("read 0xff at %q: %w", "/tmp/b", io.EOF), "beef2"),
+		},
+		{
+			fmt.Errorf("request 123e4567-e89b-12d3-a456-426614174000 failed"),
+			fmt.Errorf("request 00000000-0000-0000-0000-000000000000 failed"),
+		},
+	}
+	for i, errs := range same {
+		want := Fingerprint(errs[0])
+		if !regexp.MustCompile("^[0-9a-f]{32}$").MatchString(want) {
+			t.Errorf("%d: Fingerprint: unexpected %q", i, want)
+		}
+		for j, err := range errs[1:] {
+			if got := Fingerprint(err); got != want {
+				t.Errorf("%d.%d: Fingerprint: want: %q, got: %q", i, j+1, want, got)
+			}
+		}
+	}
+
+	different := []error{
+		newUserError(1),
+		newOrderError(1),
+		WithCode(newUserError(1), 20001),
+		Errorf("user %d not found", 1),
+		Errorf("user %s not found", "alice"),
+	}
+	seen := make(map[string]int)
+	for i, err := range different {
+		fp := Fingerprint(err)
+		if j, ok := seen[fp]; ok {
+			t.Errorf("%d: Fingerprint: same as %d: %q", i, j, fp)
+		}
+		seen[fp] = i
+	}
+}
+
+func TestIsInProjectFunc(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefixes []string
+		want     bool
+	}{
+		{"runtime.goexit", nil, false},
+		{"net/http.(*conn).serve", nil, false},
+		{"main.main", nil, true},
+		{"github.com/shipengqi/errors/sets.String.Has", nil, true},
+		{"example.com/app.run.func1", nil, true},
+		{"example/app.run", []string{"example/app"}, true},
+		{"example/app/internal/store.(*DB).Get", []string{"example/app"}, true},
+		{"example/apple.run", []string{"example/app"}, false},
+		{"github.com/lib/pq.(*conn).query", []string{"example/app"}, false},
+		{"runtime.goexit", []string{"example/app"}, false},
+		{"main.main", []string{"example/app"}, true},
+		{"github.com/lib/pq.open", []string{"example/app", "github.com/lib/"}, true},
+	}
+	for _, tt := range tests {
+		if got := isInProjectFunc(tt.name, tt.prefixes); got != tt.want {
+			t.Errorf("isInProjectFunc(%q, %q): want: %v, got: %v", tt.name, tt.prefixes, tt.want, got)
+		}
+	}
+}
+
+func TestSetInProjectPrefixes(t *testing.T) {
+	if got := inProjectPrefixes(); len(got) != 1 || got[0] != "github.com/shipengqi/errors" {
+		t.Errorf("inProjectPrefixes: want: the main module, got: %q", got)
+	}
+	defer SetInProjectPrefixes(inProjectPrefixes()...)
+
+	err := newUserError(1)
+	want := Fingerprint(err)
+	SetInProjectPrefixes("example/app")
+	if got := Fingerprint(err); got == want {
+		t.Errorf("Fingerprint: want: no in-project frames hashed, got: %q", got)
+	}
+	if got, other := Fingerprint(err), Fingerprint(newOrderError(1)); got == other {
+		t.Errorf("Fingerprint: want: different messages, got: %q", got)
+	}
+	SetInProjectPrefixes("github.com/shipengqi/errors")
+	if got := Fingerprint(err); got != want {
+		t.Errorf("Fingerprint: want: %q, got: %q", want, got)
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	if got := Timestamp(New("error")); !got.IsZero() {
+		t.Errorf("Timestamp: want: zero when disabled, got: %v", got)
+	}
+	if got := fmt.Sprintf("%+v", New("error")); strings.Contains(got, "\ntime: ") {
+		t.Errorf("Sprintf(%%+v): unexpected time when disabled: %q", got)
+	}
+
+	SetCaptureOptions(CaptureOptions{Timestamp: true})
+	defer SetCaptureOptions(CaptureOptions{})
+
+	before := time.Now()
+	root := New("error")
+	after := time.Now()
+	created := Timestamp(root)
+	if created.Before(before) || created.After(after) {
+		t.Errorf("Timestamp: %v not within [%v, %v]", created, before, after)
+	}
+
+	time.Sleep(time.Millisecond)
+	tests := []error{
+		Wrap(root, "wrapped"),
+		WithStack(WithMessage(root, "wrapped")),
+		fmt.Errorf("wrapped: %w", root),
+	}
+	for i, err := range tests {
+		if got := Timestamp(err); !got.Equal(created) {
+			t.Errorf("%d: Timestamp: want: %v, got: %v", i, created, got)
+		}
+	}
+	if got := Timestamp(Wrap(io.EOF, "read")); got.IsZero() || !got.After(created) {
+		t.Errorf("Timestamp: want: after %v, got: %v", created, got)
+	}
+	if got := Timestamp(io.EOF); !got.IsZero() {
+		t.Errorf("Timestamp: want: zero, got: %v", got)
+	}
+}
+
+func TestCaptureOptions(t *testing.T) {
+	SetCaptureOptions(CaptureOptions{Timestamp: true, GoroutineID: true, Component: "billing"})
+	defer SetCaptureOptions(CaptureOptions{})
+
+	err := Wrap(New("error"), "wrapped")
+	got := fmt.Sprintf("%+v", err)
+	want := "^error\ntime: \\d{4}-\\d\\d-\\d\\dT[^\n]+\ngoroutine: [1-9]\\d*\ncomponent: billing\ngithub.com/shipengqi/errors.TestCaptureOptions\n"
+	if !regexp.MustCompile(want).MatchString(got) {
+		t.Errorf("Sprintf(%%+v):\n got: %q\nwant: %q", got, want)
+	}
+	if n := strings.Count(got, "\ncomponent: "); n != 1 {
+		t.Errorf("Sprintf(%%+v): want: a single origin, got %d", n)
+	}
+
+	b, jerr := MarshalError(err)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	var v struct {
+		Time      time.Time `json:"time"`
+		Goroutine uint64    `json:"goroutine"`
+		Component string    `json:"component"`
+	}
+	if jerr = json.Unmarshal(b, &v); jerr != nil {
+		t.Fatal(jerr)
+	}
+	if !v.Time.Equal(Timestamp(err)) || v.Goroutine == 0 || v.Component != "billing" {
+		t.Errorf("MarshalError: unexpected %s", b)
+	}
+}
+
+func TestCaptureDisabled(t *testing.T) {
+	SetCaptureOptions(CaptureOptions{Component: "billing"})
+	SetCaptureOptions(CaptureOptions{})
+	if o := newOrigin(nil); o != nil {
+		t.Errorf("newOrigin: want: nil, got: %#v", o)
+	}
+	if f := New("error").(*fundamental); f.origin != nil {
+		t.Errorf("New: want: no origin, got: %#v", f.origin)
+	}
+}
+
+func TestGoroutineID(t *testing.T) {
+	main := goroutineID()
+	done := make(chan uint64)
+	go func() { done <- goroutineID() }()
+	other := <-done
+	if main == 0 || other == 0 || main == other {
+		t.Errorf("goroutineID: unexpected %d and %d", main, other)
+	}
+}
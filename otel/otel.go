@@ -0,0 +1,109 @@
+// Package otel records the errors of github.com/shipengqi/errors on
+// OpenTelemetry spans, keeping their code and stack trace.
+package otel
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/shipengqi/errors"
+)
+
+// Attribute keys recorded on the exception events, in addition to the
+// exception attributes of the OpenTelemetry semantic conventions.
+const (
+	CodeKey       = attribute.Key("error.code")
+	HTTPStatusKey = attribute.Key("http.response.status_code")
+	SeverityKey   = attribute.Key("error.severity")
+	IDKey         = attribute.Key("error.id")
+	ReferenceKey  = attribute.Key("error.reference")
+)
+
+const (
+	exceptionEvent         = "exception"
+	exceptionTypeKey       = attribute.Key("exception.type")
+	exceptionMessageKey    = attribute.Key("exception.message")
+	exceptionStacktraceKey = attribute.Key("exception.stacktrace")
+)
+
+// RecordError records err on span as an exception event and sets the status
+// of span to Error, described by the message of the Coder of err.
+//
+// The event has the type and message of err, its stack trace as returned by
+// StackTrace, the code and HTTP status of its Coder as returned by
+// errors.ParseCoder, its severity, and its instance ID if any.
+// If err is nil or span is not recording, RecordError does nothing.
+func RecordError(span trace.Span, err error, opts ...trace.EventOption) {
+	if err == nil || !span.IsRecording() {
+		return
+	}
+	coder := errors.ParseCoder(err)
+	root, stack := inspect(err)
+	attrs := []attribute.KeyValue{
+		exceptionTypeKey.String(reflect.TypeOf(root).String()),
+		exceptionMessageKey.String(err.Error()),
+		CodeKey.Int(coder.Code()),
+		HTTPStatusKey.Int(coder.HTTPStatus()),
+		SeverityKey.String(errors.SeverityOf(err).String()),
+	}
+	if len(stack) > 0 {
+		attrs = append(attrs, exceptionStacktraceKey.String(
+			strings.TrimPrefix(fmt.Sprintf("%+v", stack), "\n"),
+		))
+	}
+	if ref := coder.Reference(); ref != "" {
+		attrs = append(attrs, ReferenceKey.String(ref))
+	}
+	if id := errors.ID(err); id != "" {
+		attrs = append(attrs, IDKey.String(id))
+	}
+	opts = append([]trace.EventOption{trace.WithAttributes(attrs...)}, opts...)
+	span.AddEvent(exceptionEvent, opts...)
+	span.SetStatus(codes.Error, coder.String())
+}
+
+// RecordErrorContext records err on the span of ctx, see RecordError.
+func RecordErrorContext(ctx context.Context, err error, opts ...trace.EventOption) {
+	RecordError(trace.SpanFromContext(ctx), err, opts...)
+}
+
+// ContextExtractor is an errors.ContextExtractor that extracts the trace ID
+// and span ID of the span of ctx into the trace_id and span_id fields.
+// Register it with errors.RegisterContextExtractor so that they are attached
+// to the errors created by errors.NewCtx and errors.WrapCtx.
+func ContextExtractor(ctx context.Context) errors.Fields {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return errors.Fields{
+		"trace_id": sc.TraceID().String(),
+		"span_id":  sc.SpanID().String(),
+	}
+}
+
+// inspect returns the root cause of err and the stack trace recorded closest
+// to it, following both Cause and Unwrap.
+func inspect(err error) (root error, stack errors.StackTrace) {
+	for err != nil {
+		root = err
+		if v, ok := err.(interface{ StackTrace() errors.StackTrace }); ok {
+			stack = v.StackTrace()
+		}
+		switch x := err.(type) {
+		case interface{ Cause() error }:
+			err = x.Cause()
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		default:
+			err = nil
+		}
+	}
+	return root, stack
+}
@@ -0,0 +1,138 @@
+package otel
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/shipengqi/errors"
+)
+
+type testCoder struct{}
+
+func (testCoder) Code() int         { return 30001 }
+func (testCoder) HTTPStatus() int   { return 404 }
+func (testCoder) String() string    { return "Not found" }
+func (testCoder) Reference() string { return "https://example.com/errors/30001" }
+
+func init() {
+	errors.Register(testCoder{})
+}
+
+func record(t *testing.T, err error) sdktrace.ReadOnlySpan {
+	t.Helper()
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	_, span := tp.Tracer("test").Start(context.Background(), "op")
+	RecordError(span, err)
+	span.End()
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("want 1 span, got %d", len(spans))
+	}
+	return spans[0]
+}
+
+func attrs(kvs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value, len(kvs))
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestRecordError(t *testing.T) {
+	err := errors.WithCode(errors.Wrap(errors.New("no rows"), "find user"), 30001)
+	span := record(t, err)
+
+	if got := span.Status(); got.Code != codes.Error || got.Description != "Not found" {
+		t.Errorf("status: want {Error Not found}, got %+v", got)
+	}
+	events := span.Events()
+	if len(events) != 1 {
+		t.Fatalf("want 1 event, got %d", len(events))
+	}
+	if events[0].Name != "exception" {
+		t.Errorf("event name: want %q, got %q", "exception", events[0].Name)
+	}
+	got := attrs(events[0].Attributes)
+	tests := []struct {
+		key  attribute.Key
+		want string
+	}{
+		{"exception.type", "*errors.fundamental"},
+		{"exception.message", "code: 30001, find user: no rows"},
+		{CodeKey, "30001"},
+		{HTTPStatusKey, "404"},
+		{SeverityKey, "error"},
+		{ReferenceKey, "https://example.com/errors/30001"},
+	}
+	for _, tt := range tests {
+		if v := got[tt.key].Emit(); v != tt.want {
+			t.Errorf("%s: want %q, got %q", tt.key, tt.want, v)
+		}
+	}
+	stack := got["exception.stacktrace"].AsString()
+	if !regexp.MustCompile(`^github.com/shipengqi/errors/otel.TestRecordError\n\t.+/otel_test.go:\d+`).MatchString(stack) {
+		t.Errorf("exception.stacktrace: got %q", stack)
+	}
+}
+
+func TestRecordErrorUnknownCode(t *testing.T) {
+	span := record(t, errors.New("boom"))
+	got := attrs(span.Events()[0].Attributes)
+	if v := got[CodeKey].AsInt64(); v != 1 {
+		t.Errorf("code: want 1, got %d", v)
+	}
+	if v := got[HTTPStatusKey].AsInt64(); v != 500 {
+		t.Errorf("http status: want 500, got %d", v)
+	}
+	if _, ok := got[ReferenceKey]; ok {
+		t.Errorf("reference: want none, got %v", got[ReferenceKey].Emit())
+	}
+	if d := span.Status().Description; d != "Internal server error" {
+		t.Errorf("status: want %q, got %q", "Internal server error", d)
+	}
+}
+
+func TestRecordErrorNil(t *testing.T) {
+	span := record(t, nil)
+	if n := len(span.Events()); n != 0 {
+		t.Errorf("want no event, got %d", n)
+	}
+	if got := span.Status().Code; got != codes.Unset {
+		t.Errorf("status: want Unset, got %v", got)
+	}
+}
+
+func TestRecordErrorNotRecording(t *testing.T) {
+	// must not panic on the no-op span
+	RecordError(trace.SpanFromContext(context.Background()), errors.New("boom"))
+}
+
+func TestContextExtractor(t *testing.T) {
+	if got := ContextExtractor(context.Background()); got != nil {
+		t.Errorf("want nil fields, got %v", got)
+	}
+
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+	defer span.End()
+
+	errors.RegisterContextExtractor(ContextExtractor)
+	err := errors.NewCtx(ctx, "boom")
+	fields := errors.FieldsOf(err)
+	sc := span.SpanContext()
+	if got := fields["trace_id"]; got != sc.TraceID().String() {
+		t.Errorf("trace_id: want %s, got %v", sc.TraceID(), got)
+	}
+	if got := fields["span_id"]; got != sc.SpanID().String() {
+		t.Errorf("span_id: want %s, got %v", sc.SpanID(), got)
+	}
+}
@@ -0,0 +1,107 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WithPublicMessage annotates err with a message that is safe to show to the
+// users, such as in an HTTP response. The message does not change the result
+// of Error, which keeps the internal diagnostic message of err.
+// If err is nil, WithPublicMessage returns nil.
+func WithPublicMessage(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	return &withPublicMessage{
+		cause: err,
+		msg:   message,
+	}
+}
+
+type withPublicMessage struct {
+	cause error
+	msg   string
+}
+
+func (w *withPublicMessage) Error() string         { return redact(plainMessage(w.cause)) }
+func (w *withPublicMessage) Cause() error          { return w.cause }
+func (w *withPublicMessage) PublicMessage() string { return w.msg }
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (w *withPublicMessage) Unwrap() error { return w.cause }
+
+func (w *withPublicMessage) plainMessage() string { return plainMessage(w.cause) }
+
+func (w *withPublicMessage) writePlain(out io.Writer) {
+	writePlain(out, w.cause)
+	_, _ = fmt.Fprintf(out, "\npublic: %s", w.msg)
+}
+
+func (w *withPublicMessage) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			formatPlus(s, w)
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(s, w.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", w.Error())
+	}
+}
+
+type publicMessager interface {
+	PublicMessage() string
+}
+
+// PublicMessage returns the message of err that is safe to show to the users.
+// It is the message of the outermost error in err's chain that implements
+// publicMessager, such as the ones set with WithPublicMessage, or,
+// if there is none, the String of the Coder of err as returned by ParseCoder.
+// A String with format verbs, such as the format of a Template defined
+// without DefineMessage, is replaced by the String of the fallback Coder.
+// The messages added with Wrap, WithMessage and the like, and the message
+// of the cause, are never part of the result.
+// If err is nil, PublicMessage returns an empty string.
+func PublicMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	var msg string
+	if walk(err, func(err error) bool {
+		if v, ok := err.(publicMessager); ok {
+			msg = v.PublicMessage()
+			return true
+		}
+		return false
+	}) {
+		return msg
+	}
+	return coderMessage(coderOf(err))
+}
+
+// coderMessage returns the String of coder, or the String of the fallback
+// Coder if it has format verbs and is not meant to be shown as is.
+func coderMessage(coder Coder) string {
+	msg := coder.String()
+	if hasFormatVerbs(msg) {
+		return fallbackCoder().String()
+	}
+	return msg
+}
+
+// hasFormatVerbs reports whether msg has a verb of fmt. The verbs with a space
+// flag are left out, as in "50% off".
+func hasFormatVerbs(msg string) bool {
+	verbs, _ := templateVerbs(msg)
+	for _, verb := range verbs {
+		if !strings.Contains(verb, " ") {
+			return true
+		}
+	}
+	return false
+}
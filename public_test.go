@@ -0,0 +1,76 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestPublicMessage(t *testing.T) {
+	mockCode := defaultCoder{code: 20001, status: 404, msg: "Not found"}
+	Register(mockCode)
+	defer Unregister(mockCode)
+
+	query := "SELECT * FROM users WHERE email = 'alice@example.com'"
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{io.EOF, "Internal server error"},
+		{Wrap(io.EOF, query), "Internal server error"},
+		{WithCode(Wrap(io.EOF, query), 20001), "Not found"},
+		{WithMessage(WithCode(io.EOF, 20001), query), "Not found"},
+		{WithPublicMessage(Wrap(io.EOF, query), "Try again later"), "Try again later"},
+		{Wrapf(WithPublicMessage(WithCode(io.EOF, 20001), "User not found"), "run %q", query), "User not found"},
+		{fmt.Errorf("%s: %w", query, WithPublicMessage(io.EOF, "Try again later")), "Try again later"},
+		{WithPublicMessage(WithPublicMessage(io.EOF, "inner"), "outer"), "outer"},
+	}
+	for i, tt := range tests {
+		got := PublicMessage(tt.err)
+		if got != tt.want {
+			t.Errorf("%d: PublicMessage: want: %q, got: %q", i, tt.want, got)
+		}
+		if strings.Contains(got, query) {
+			t.Errorf("%d: PublicMessage leaked the internal message: %q", i, got)
+		}
+	}
+}
+
+func TestWithPublicMessage(t *testing.T) {
+	if got := WithPublicMessage(nil, "message"); got != nil {
+		t.Errorf("WithPublicMessage(nil, \"message\"): got %#v, expected nil", got)
+	}
+
+	err := WithPublicMessage(Wrap(io.EOF, "read"), "Try again later")
+	if got, want := err.Error(), "read: EOF"; got != want {
+		t.Errorf("Error(): want: %q, got: %q", want, got)
+	}
+	if got := fmt.Sprintf("%+v", err); !strings.HasSuffix(got, "\npublic: Try again later") {
+		t.Errorf("Sprintf(%%+v): unexpected %q", got)
+	}
+	if got, want := fmt.Sprintf("%q", err), `"read: EOF"`; got != want {
+		t.Errorf("Sprintf(%%q): want: %q, got: %q", want, got)
+	}
+}
+
+func TestHasFormatVerbs(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want bool
+	}{
+		{"", false},
+		{"Not found", false},
+		{"user %s not found", true},
+		{"tenant %03d", true},
+		{"100%%", false},
+		{"50% off", false},
+		{"trailing %", false},
+	}
+	for _, tt := range tests {
+		if got := hasFormatVerbs(tt.msg); got != tt.want {
+			t.Errorf("hasFormatVerbs(%q): want: %v, got: %v", tt.msg, tt.want, got)
+		}
+	}
+}
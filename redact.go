@@ -0,0 +1,186 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"sync/atomic"
+)
+
+// Redacted replaces the sensitive values in the output of errors.
+const Redacted = "[REDACTED]"
+
+// UnsafeVerb is the verb that formats the value of a Secret instead of
+// Redacted, for example Errorf("token %U", Secret(token)).
+const UnsafeVerb = 'U'
+
+// Redactor rewrites a message to remove the sensitive values it contains.
+type Redactor func(s string) string
+
+var _redactor atomic.Value
+
+// SetRedactor sets the Redactor applied to the messages of the errors of this
+// package: to the result of Error, to the output of the fmt verbs, and to the
+// message and string fields of their JSON encoding by MarshalError.
+// A nil Redactor disables redaction, which is the default.
+func SetRedactor(r Redactor) {
+	_redactor.Store(r)
+}
+
+// Redact applies the Redactor set with SetRedactor to s. It is meant for the
+// packages exporting errors in their own formats.
+func Redact(s string) string {
+	return redact(s)
+}
+
+// redact applies the Redactor set with SetRedactor to s.
+func redact(s string) string {
+	if r, _ := _redactor.Load().(Redactor); r != nil {
+		return r(s)
+	}
+	return s
+}
+
+// plainError is implemented by the errors of this package. Their Error and
+// Format methods apply the Redactor to their whole output, which is built
+// from the output of their causes without it, so that the Redactor runs once
+// whatever the number of errors in the chain.
+type plainError interface {
+	// plainMessage returns the result of Error without the Redactor applied.
+	plainMessage() string
+
+	// writePlain writes the %+v output without the Redactor applied.
+	writePlain(w io.Writer)
+}
+
+// plainMessage returns the message of err without the Redactor applied, if
+// err is an error of this package.
+func plainMessage(err error) string {
+	if v, ok := err.(plainError); ok {
+		return v.plainMessage()
+	}
+	return err.Error()
+}
+
+// writePlain writes err formatted with %+v to w, without the Redactor applied
+// if err is an error of this package.
+func writePlain(w io.Writer, err error) {
+	if v, ok := err.(plainError); ok {
+		v.writePlain(w)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "%+v", err)
+}
+
+// formatPlus writes the %+v output of err to s with the Redactor applied.
+func formatPlus(s fmt.State, err plainError) {
+	var buf bytes.Buffer
+	err.writePlain(&buf)
+	_, _ = io.WriteString(s, redact(buf.String()))
+}
+
+// ChainRedactors returns a Redactor that applies rs in order.
+func ChainRedactors(rs ...Redactor) Redactor {
+	return func(s string) string {
+		for _, r := range rs {
+			s = r(s)
+		}
+		return s
+	}
+}
+
+// RegexpRedactor returns a Redactor that replaces the matches of re with
+// repl. Inside repl, $ signs are interpreted as in Regexp.Expand.
+func RegexpRedactor(re *regexp.Regexp, repl string) Redactor {
+	return func(s string) string {
+		return re.ReplaceAllString(s, repl)
+	}
+}
+
+var (
+	emailRegexp      = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	cardNumberRegexp = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	tokenRegexps     = []struct {
+		re   *regexp.Regexp
+		repl string
+	}{
+		{regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`), "$1 " + Redacted},
+		{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), Redacted},
+		{
+			regexp.MustCompile(`(?i)\b(password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key)(\s*[=:]\s*)("[^"]*"|'[^']*'|[^\s,;&]+)`),
+			"$1$2" + Redacted,
+		},
+	}
+)
+
+// RedactEmails is a Redactor that replaces email addresses.
+func RedactEmails(s string) string {
+	return emailRegexp.ReplaceAllString(s, Redacted)
+}
+
+// RedactCardNumbers is a Redactor that replaces payment card numbers, with
+// or without space or dash separators. Only the numbers that pass the Luhn
+// checksum are replaced.
+func RedactCardNumbers(s string) string {
+	return cardNumberRegexp.ReplaceAllStringFunc(s, func(match string) string {
+		if luhn(match) {
+			return Redacted
+		}
+		return match
+	})
+}
+
+// RedactTokens is a Redactor that replaces bearer and basic credentials,
+// JSON Web Tokens, and the values of key=value or key: value pairs whose key
+// names a password, secret, token or API key.
+func RedactTokens(s string) string {
+	for _, t := range tokenRegexps {
+		s = t.re.ReplaceAllString(s, t.repl)
+	}
+	return s
+}
+
+// luhn reports whether the digits of s pass the Luhn checksum.
+func luhn(s string) bool {
+	var sum, n int
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n > 0 && sum%10 == 0
+}
+
+// Secret wraps a sensitive value passed to Errorf, Wrapf and the like so
+// that it is formatted as Redacted, whatever the verb, unless UnsafeVerb is
+// used. Secret values are also encoded as Redacted in JSON.
+func Secret(value interface{}) fmt.Formatter {
+	return secret{value: value}
+}
+
+type secret struct {
+	value interface{}
+}
+
+func (s secret) Format(st fmt.State, verb rune) {
+	if verb == UnsafeVerb {
+		_, _ = fmt.Fprint(st, s.value)
+		return
+	}
+	_, _ = io.WriteString(st, Redacted)
+}
+
+func (s secret) String() string { return Redacted }
+
+func (s secret) MarshalText() ([]byte, error) { return []byte(Redacted), nil }
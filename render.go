@@ -0,0 +1,320 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shipengqi/errors/internal/chain"
+)
+
+// ColorMode tells Render whether to use ANSI colors.
+type ColorMode int
+
+const (
+	// ColorAuto uses colors when writing to a terminal, unless the NO_COLOR
+	// environment variable is set or TERM is "dumb".
+	ColorAuto ColorMode = iota
+	// ColorAlways always uses colors.
+	ColorAlways
+	// ColorNever never uses colors.
+	ColorNever
+)
+
+// StackMode tells Render how much of the stack traces to show.
+type StackMode int
+
+const (
+	// StackCollapsed shows the top frame of each stack trace and the number of
+	// frames hidden.
+	StackCollapsed StackMode = iota
+	// StackExpanded shows all the frames of each stack trace.
+	StackExpanded
+	// StackHidden shows no stack trace.
+	StackHidden
+)
+
+// DefaultRenderWidth is the width used by Render when none is given and the
+// COLUMNS environment variable is not set.
+const DefaultRenderWidth = 80
+
+// minRenderWidth is the smallest width Render wraps text to.
+const minRenderWidth = 40
+
+// RenderOptions are the options of Render. The zero RenderOptions is ready
+// to use.
+type RenderOptions struct {
+	Color ColorMode
+	Stack StackMode
+
+	// Width is the number of columns the output is wrapped to. If zero, it is
+	// read from the COLUMNS environment variable, or else DefaultRenderWidth.
+	Width int
+}
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiDim   = "\x1b[2m"
+	ansiRed   = "\x1b[31m"
+	ansiCyan  = "\x1b[36m"
+)
+
+// Render writes err to w in a form meant to be read by the users of command
+// line tools: a boxed summary with the message, the code and the reference
+// of its Coder, followed by the chain of its causes with their stack traces.
+// The members of an Aggregate are rendered as a list, each with its own
+// chain. The runtime and testing frames are left out of the stack traces.
+// Render writes nothing if err is nil.
+func Render(w io.Writer, err error, opts RenderOptions) error {
+	if err == nil {
+		return nil
+	}
+	r := &renderer{
+		color: useColor(w, opts.Color),
+		stack: opts.Stack,
+		width: renderWidth(opts.Width),
+	}
+	r.summary(err)
+	r.buf.WriteString("\n")
+	r.chain(err, "", false)
+	_, werr := w.Write(r.buf.Bytes())
+	return werr
+}
+
+func useColor(w io.Writer, mode ColorMode) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+func renderWidth(width int) int {
+	if width <= 0 {
+		width, _ = strconv.Atoi(os.Getenv("COLUMNS"))
+	}
+	if width <= 0 {
+		width = DefaultRenderWidth
+	}
+	if width < minRenderWidth {
+		width = minRenderWidth
+	}
+	return width
+}
+
+type renderer struct {
+	buf   bytes.Buffer
+	color bool
+	stack StackMode
+	width int
+}
+
+// paint returns s with the ANSI attributes attrs, if colors are enabled.
+func (r *renderer) paint(s string, attrs ...string) string {
+	if !r.color || len(attrs) == 0 {
+		return s
+	}
+	return strings.Join(attrs, "") + s + ansiReset
+}
+
+// summary writes the box summarizing err.
+func (r *renderer) summary(err error) {
+	type line struct {
+		text  string
+		attrs []string
+	}
+	inner := r.width - 4
+	var lines []line
+	add := func(s string, attrs ...string) {
+		for _, l := range wrapText(s, inner) {
+			lines = append(lines, line{l, attrs})
+		}
+	}
+
+	add("Error: "+err.Error(), ansiBold, ansiRed)
+	coder := coderOf(err)
+	add(fmt.Sprintf("Code: %d, HTTP %d, %s", coder.Code(), coder.HTTPStatus(), coder.String()))
+	if ref := coder.Reference(); ref != "" {
+		add("See: "+ref, ansiCyan)
+	}
+	if id := ID(err); id != "" {
+		add("ID: " + id)
+	}
+	if fields := FieldsOf(err); len(fields) > 0 {
+		add("Fields: " + redact(fields.String()))
+	}
+
+	border := strings.Repeat("─", r.width-2)
+	r.buf.WriteString(r.paint("╭"+border+"╮", ansiRed) + "\n")
+	for _, l := range lines {
+		pad := ""
+		if n := inner - utf8.RuneCountInString(l.text); n > 0 {
+			pad = strings.Repeat(" ", n)
+		}
+		r.buf.WriteString(r.paint("│", ansiRed) + " " + r.paint(l.text, l.attrs...) + pad + " " + r.paint("│", ansiRed) + "\n")
+	}
+	r.buf.WriteString(r.paint("╰"+border+"╯", ansiRed) + "\n")
+}
+
+// renderLink is an error of a chain that has a message of its own.
+type renderLink struct {
+	msg   string
+	stack StackTrace
+	group []error
+}
+
+// renderLinks returns the links of the chain of err, the outermost first.
+// The stack trace recorded by a wrapper goes to the next link with a message,
+// which is where Wrap records it.
+func renderLinks(err error) []renderLink {
+	var (
+		links   []renderLink
+		pending StackTrace
+	)
+	for err != nil {
+		if v, ok := err.(interface{ StackTrace() StackTrace }); ok {
+			pending = v.StackTrace()
+		}
+		var group []error
+		switch x := err.(type) {
+		case Aggregate:
+			group = x.Errors()
+		case interface{ Unwrap() []error }:
+			group = x.Unwrap()
+		}
+		if group != nil {
+			msg := fmt.Sprintf("%d errors", len(group))
+			return append(links, renderLink{msg: msg, stack: pending, group: group})
+		}
+
+		cause := chain.Next(err)
+		if msg, ok := chain.OwnMessage(err, cause); ok {
+			links = append(links, renderLink{msg: msg, stack: pending})
+			pending = nil
+		}
+		err = cause
+	}
+	return links
+}
+
+// chain writes the links of the chain of err, each line starting with
+// indent. The links are numbered, except for the members of an Aggregate
+// which are written as a list item followed by their causes.
+func (r *renderer) chain(err error, indent string, member bool) {
+	links := renderLinks(err)
+	num := len(strconv.Itoa(len(links)))
+	for i, link := range links {
+		var prefix string
+		switch {
+		case !member:
+			prefix = fmt.Sprintf("%*d. ", num, i+1)
+		case i == 0:
+			prefix = "• "
+		default:
+			prefix = "  ↳ "
+		}
+		cont := indent + strings.Repeat(" ", utf8.RuneCountInString(prefix))
+		r.text(link.msg, indent+prefix, cont, ansiBold)
+		r.frames(link.stack, cont+"  ")
+		for _, m := range link.group {
+			r.chain(m, cont, true)
+		}
+	}
+}
+
+// text writes s wrapped to the width, the first line starting with first and
+// the next ones with rest.
+func (r *renderer) text(s, first, rest string, attrs ...string) {
+	width := r.width - utf8.RuneCountInString(first)
+	for i, l := range wrapText(s, width) {
+		if i == 0 {
+			r.buf.WriteString(first)
+		} else {
+			r.buf.WriteString(rest)
+		}
+		r.buf.WriteString(r.paint(l, attrs...) + "\n")
+	}
+}
+
+// frames writes the frames of st according to the StackMode.
+func (r *renderer) frames(st StackTrace, indent string) {
+	if r.stack == StackHidden {
+		return
+	}
+	var frames []Frame
+	for _, f := range st {
+		name := f.name()
+		if strings.HasPrefix(name, "runtime.") || strings.HasPrefix(name, "testing.") {
+			continue
+		}
+		frames = append(frames, f)
+	}
+	if len(frames) == 0 {
+		return
+	}
+	hidden := 0
+	if r.stack == StackCollapsed {
+		hidden = len(frames) - 1
+		frames = frames[:1]
+	}
+	for i, f := range frames {
+		marker := "  "
+		if i == 0 {
+			marker = "▸ "
+			if r.stack == StackExpanded {
+				marker = "▾ "
+			}
+		}
+		at := fmt.Sprintf("at %s (%s:%d)", path.Base(f.name()), path.Base(f.file()), f.line())
+		if hidden > 0 {
+			at += fmt.Sprintf(" +%d more", hidden)
+		}
+		r.buf.WriteString(indent + marker + r.paint(at, ansiDim) + "\n")
+	}
+}
+
+// wrapText splits s into lines of at most width runes, breaking between
+// words. Words longer than width are broken at rune boundaries.
+func wrapText(s string, width int) []string {
+	var (
+		lines []string
+		line  string
+	)
+	for _, word := range strings.Fields(s) {
+		for width > 0 && utf8.RuneCountInString(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			runes := []rune(word)
+			lines = append(lines, string(runes[:width]))
+			word = string(runes[width:])
+		}
+		switch {
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	return append(lines, line)
+}
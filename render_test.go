@@ -0,0 +1,143 @@
+package errors
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+type renderCoder struct{}
+
+func (renderCoder) Code() int         { return 20301 }
+func (renderCoder) HTTPStatus() int   { return 404 }
+func (renderCoder) String() string    { return "User not found" }
+func (renderCoder) Reference() string { return "https://example.com/errors/20301" }
+
+func findRenderUser() error {
+	err := Wrap(New("no rows in result set"), "find user 42")
+	return WithFields(WithCode(err, 20301), Fields{"tenant": "acme"})
+}
+
+func importRenderRows() error {
+	return NewAggregate([]error{
+		New("invalid row 3"),
+		Wrap(io.ErrUnexpectedEOF, "read row 7"),
+	})
+}
+
+func TestRender(t *testing.T) {
+	Register(renderCoder{})
+	defer Unregister(renderCoder{})
+
+	tests := []struct {
+		name string
+		err  error
+		opts RenderOptions
+	}{
+		{"wrapped", findRenderUser(), RenderOptions{Color: ColorNever}},
+		{"expanded", findRenderUser(), RenderOptions{Color: ColorNever, Stack: StackExpanded}},
+		{"hidden", findRenderUser(), RenderOptions{Color: ColorNever, Stack: StackHidden}},
+		{"color", findRenderUser(), RenderOptions{Color: ColorAlways, Stack: StackHidden}},
+		{"aggregate", importRenderRows(), RenderOptions{Color: ColorNever}},
+		{"narrow", Wrap(New("the quick brown fox jumps over the lazy dog again and again"),
+			"while rendering a rather long message"), RenderOptions{Color: ColorNever, Width: 40, Stack: StackHidden}},
+		{"longword", New("fetch https://example.com/api/v1/users/42/orders?page=2 failed"),
+			RenderOptions{Color: ColorNever, Width: 40, Stack: StackHidden}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Render(&buf, tt.err, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			file := filepath.Join("testdata", "render", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(file, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != string(want) {
+				t.Errorf("%s: want:\n%s\ngot:\n%s", file, want, got)
+			}
+		})
+	}
+}
+
+func TestRenderNil(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, nil, RenderOptions{}); err != nil || buf.Len() != 0 {
+		t.Errorf("want no output, got %q, %v", buf.String(), err)
+	}
+}
+
+func TestUseColor(t *testing.T) {
+	var buf bytes.Buffer
+	tests := []struct {
+		mode ColorMode
+		want bool
+	}{
+		{ColorAuto, false},
+		{ColorAlways, true},
+		{ColorNever, false},
+	}
+
+	for _, tt := range tests {
+		if got := useColor(&buf, tt.mode); got != tt.want {
+			t.Errorf("useColor(%d): want %t, got %t", tt.mode, tt.want, got)
+		}
+	}
+}
+
+func TestRenderWidth(t *testing.T) {
+	tests := []struct {
+		width   int
+		columns string
+		want    int
+	}{
+		{0, "", DefaultRenderWidth},
+		{0, "120", 120},
+		{100, "120", 100},
+		{10, "", minRenderWidth},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("COLUMNS", tt.columns)
+		if got := renderWidth(tt.width); got != tt.want {
+			t.Errorf("renderWidth(%d) with COLUMNS=%q: want %d, got %d", tt.width, tt.columns, tt.want, got)
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  []string
+	}{
+		{"", 10, []string{""}},
+		{"a b c", 10, []string{"a b c"}},
+		{"aaa bbb ccc", 7, []string{"aaa bbb", "ccc"}},
+		{"aaaaaaaaaaaa b", 5, []string{"aaaaa", "aaaaa", "aa b"}},
+		{"a bbbbbb", 5, []string{"a", "bbbbb", "b"}},
+		{"ééééé", 2, []string{"éé", "éé", "é"}},
+		{"aaaa", 2, []string{"aa", "aa"}},
+	}
+
+	for _, tt := range tests {
+		got := wrapText(tt.s, tt.width)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("wrapText(%q, %d): want %q, got %q", tt.s, tt.width, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+)
+
+type retryer interface {
+	Retryable() bool
+}
+
+type temporary interface {
+	Temporary() bool
+}
+
+// Retryable reports whether err is worth retrying.
+//
+// The first error in err's chain that implements one of the following
+// interfaces decides:
+//
+//	type retryer interface {
+//	        Retryable() bool
+//	}
+//
+//	type temporary interface {
+//	        Temporary() bool
+//	}
+//
+// An error with a code decides too if its registered Coder implements
+// retryer. If nothing in the chain decides, Retryable returns false.
+func Retryable(err error) bool {
+	var retryable bool
+	walk(err, func(err error) bool {
+		switch x := err.(type) {
+		case retryer:
+			retryable = x.Retryable()
+			return true
+		case temporary:
+			retryable = x.Temporary()
+			return true
+		}
+		if v, ok := err.(icoder); ok {
+			coder, _ := Lookup(v.Code())
+			if r, ok := coder.(retryer); ok {
+				retryable = r.Retryable()
+				return true
+			}
+		}
+		return false
+	})
+	return retryable
+}
+
+// WithRetryable annotates err with whether it is worth retrying.
+// If err is nil, WithRetryable returns nil.
+func WithRetryable(err error, retryable bool) error {
+	if err == nil {
+		return nil
+	}
+	return &withRetryable{
+		cause:     err,
+		retryable: retryable,
+	}
+}
+
+type withRetryable struct {
+	cause     error
+	retryable bool
+}
+
+func (w *withRetryable) Error() string   { return redact(plainMessage(w.cause)) }
+func (w *withRetryable) Cause() error    { return w.cause }
+func (w *withRetryable) Retryable() bool { return w.retryable }
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (w *withRetryable) Unwrap() error { return w.cause }
+
+func (w *withRetryable) plainMessage() string { return plainMessage(w.cause) }
+
+func (w *withRetryable) writePlain(out io.Writer) { writePlain(out, w.cause) }
+
+func (w *withRetryable) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			formatPlus(s, w)
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(s, w.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", w.Error())
+	}
+}
+
+// RetryPolicy configures how Retry calls a function again after a failure.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of calls, including the first one.
+	// Values lower than 1 are treated as 1.
+	MaxAttempts int
+
+	// InitialInterval is the delay before the second call.
+	InitialInterval time.Duration
+
+	// MaxInterval caps the delay between calls. Zero means no cap.
+	MaxInterval time.Duration
+
+	// Multiplier grows the delay after each call. Values lower than 1 keep
+	// the delay constant.
+	Multiplier float64
+
+	// Jitter randomizes each delay by up to the given fraction of it, in
+	// both directions. It should be between 0 and 1.
+	Jitter float64
+}
+
+// DefaultRetryPolicy is a RetryPolicy suitable for most remote calls.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:     5,
+	InitialInterval: 100 * time.Millisecond,
+	MaxInterval:     10 * time.Second,
+	Multiplier:      2,
+	Jitter:          0.2,
+}
+
+// delay returns the randomized delay for interval.
+func (p RetryPolicy) delay(interval time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return interval
+	}
+	//nolint:gosec // jitter does not need a cryptographically secure source
+	delta := (rand.Float64()*2 - 1) * p.Jitter * float64(interval)
+	return interval + time.Duration(delta)
+}
+
+// next returns the interval that follows interval.
+func (p RetryPolicy) next(interval time.Duration) time.Duration {
+	if p.Multiplier > 1 {
+		interval = time.Duration(float64(interval) * p.Multiplier)
+	}
+	if p.MaxInterval > 0 && interval > p.MaxInterval {
+		interval = p.MaxInterval
+	}
+	return interval
+}
+
+// Retry calls fn until it succeeds, returns an error that is not Retryable,
+// or the attempts of policy are exhausted, waiting with exponential backoff
+// and jitter between calls.
+// Retry returns nil if fn eventually succeeds, otherwise an Aggregate of the
+// error of every attempt. If ctx is done while waiting, ctx.Err() is the
+// last error of the Aggregate.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) Aggregate {
+	var errs []error
+	interval := policy.InitialInterval
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+		if attempt >= policy.MaxAttempts || !Retryable(err) {
+			break
+		}
+		timer := time.NewTimer(policy.delay(interval))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			errs = append(errs, ctx.Err())
+			return NewAggregate(errs)
+		case <-timer.C:
+		}
+		interval = policy.next(interval)
+	}
+	return NewAggregate(errs)
+}
@@ -0,0 +1,188 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+type temporaryError struct{ temporary bool }
+
+func (temporaryError) Error() string     { return "temporary" }
+func (e temporaryError) Temporary() bool { return e.temporary }
+
+type retryableCoder struct {
+	defaultCoder
+	retryable bool
+}
+
+func (c retryableCoder) Retryable() bool { return c.retryable }
+
+func TestRetryable(t *testing.T) {
+	codes := []Coder{
+		retryableCoder{defaultCoder{code: 20001, status: 503, msg: "unavailable"}, true},
+		retryableCoder{defaultCoder{code: 20002, status: 400, msg: "bad request"}, false},
+	}
+	for _, v := range codes {
+		Register(v)
+	}
+	defer func() {
+		for _, v := range codes {
+			Unregister(v)
+		}
+	}()
+
+	runs := []struct {
+		expected bool
+		err      error
+	}{
+		{false, nil},
+		{false, io.EOF},
+		{true, WithRetryable(io.EOF, true)},
+		{false, WithRetryable(io.EOF, false)},
+		{true, Wrap(WithRetryable(io.EOF, true), "read")},
+		{true, fmt.Errorf("read: %w", WithRetryable(io.EOF, true))},
+		{false, WithRetryable(WithRetryable(io.EOF, true), false)},
+		{true, temporaryError{true}},
+		{false, Wrap(temporaryError{false}, "dial")},
+		{true, WithCode(io.EOF, 20001)},
+		{false, Wrap(WithCode(io.EOF, 20002), "read")},
+		{false, WithCode(io.EOF, 99999)},
+		{false, WithRetryable(WithCode(io.EOF, 20001), false)},
+	}
+	for i, r := range runs {
+		if got := Retryable(r.err); got != r.expected {
+			t.Errorf("%d: Retryable: want: %v, got: %v", i, r.expected, got)
+		}
+	}
+}
+
+func TestWithRetryableNil(t *testing.T) {
+	if got := WithRetryable(nil, true); got != nil {
+		t.Errorf("WithRetryable(nil, true): got %#v, expected nil", got)
+	}
+}
+
+func TestWithRetryableFormat(t *testing.T) {
+	err := WithRetryable(io.EOF, true)
+	if got := err.Error(); got != "EOF" {
+		t.Errorf("Error(): want: %q, got: %q", "EOF", got)
+	}
+	for format, want := range map[string]string{"%s": "EOF", "%v": "EOF", "%+v": "EOF", "%q": `"EOF"`} {
+		if got := fmt.Sprintf(format, err); got != want {
+			t.Errorf("Sprintf(%q): want: %q, got: %q", format, want, got)
+		}
+	}
+	if !errors.Is(err, io.EOF) {
+		t.Error("errors.Is: want: true, got: false")
+	}
+}
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:     4,
+	InitialInterval: time.Millisecond,
+	MaxInterval:     2 * time.Millisecond,
+	Multiplier:      2,
+	Jitter:          0.5,
+}
+
+func TestRetry(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var calls int
+		agg := Retry(context.Background(), testRetryPolicy, func(context.Context) error {
+			calls++
+			if calls < 3 {
+				return WithRetryable(fmt.Errorf("attempt %d", calls), true)
+			}
+			return nil
+		})
+		if agg != nil {
+			t.Errorf("expected nil, got %v", agg)
+		}
+		if calls != 3 {
+			t.Errorf("expected 3 calls, got %d", calls)
+		}
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		var calls int
+		agg := Retry(context.Background(), testRetryPolicy, func(context.Context) error {
+			calls++
+			return WithRetryable(fmt.Errorf("attempt %d", calls), true)
+		})
+		if calls != 4 {
+			t.Errorf("expected 4 calls, got %d", calls)
+		}
+		if agg == nil || agg.Error() != "[attempt 1, attempt 2, attempt 3, attempt 4]" {
+			t.Errorf("unexpected aggregate: %v", agg)
+		}
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		var calls int
+		agg := Retry(context.Background(), testRetryPolicy, func(context.Context) error {
+			calls++
+			if calls == 2 {
+				return io.EOF
+			}
+			return WithRetryable(fmt.Errorf("attempt %d", calls), true)
+		})
+		if calls != 2 {
+			t.Errorf("expected 2 calls, got %d", calls)
+		}
+		if agg == nil || len(agg.Errors()) != 2 || !errors.Is(agg, io.EOF) {
+			t.Errorf("unexpected aggregate: %v", agg)
+		}
+	})
+
+	t.Run("zero policy", func(t *testing.T) {
+		var calls int
+		agg := Retry(context.Background(), RetryPolicy{}, func(context.Context) error {
+			calls++
+			return WithRetryable(io.EOF, true)
+		})
+		if calls != 1 || agg == nil || len(agg.Errors()) != 1 {
+			t.Errorf("expected a single call, got %d: %v", calls, agg)
+		}
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var calls int
+		policy := testRetryPolicy
+		policy.InitialInterval = time.Hour
+		agg := Retry(ctx, policy, func(context.Context) error {
+			calls++
+			cancel()
+			return WithRetryable(io.EOF, true)
+		})
+		if calls != 1 {
+			t.Errorf("expected 1 call, got %d", calls)
+		}
+		if agg == nil || len(agg.Errors()) != 2 || !errors.Is(agg, context.Canceled) {
+			t.Errorf("unexpected aggregate: %v", agg)
+		}
+	})
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{InitialInterval: 10, MaxInterval: 35, Multiplier: 2}
+	var got []time.Duration
+	for i, interval := 0, p.InitialInterval; i < 4; i, interval = i+1, p.next(interval) {
+		got = append(got, interval)
+	}
+	want := []time.Duration{10, 20, 35, 35}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("want: %v, got: %v", want, got)
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if d := p.delay(100); d < 50 || d > 150 {
+			t.Fatalf("delay out of range: %v", d)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefineOption is an option of Define.
+type DefineOption func(s *Sentinel)
+
+// DefineHTTPStatus sets the HTTP status of a Sentinel, which is
+// http.StatusInternalServerError by default.
+func DefineHTTPStatus(status int) DefineOption {
+	return func(s *Sentinel) { s.status = status }
+}
+
+// DefineReference sets the reference document of a Sentinel.
+func DefineReference(ref string) DefineOption {
+	return func(s *Sentinel) { s.ref = ref }
+}
+
+// DefineNamespace sets the namespace of a Sentinel, see NamespacedCoder.
+func DefineNamespace(ns string) DefineOption {
+	return func(s *Sentinel) { s.ns = ns }
+}
+
+// DefineMessage sets the user-facing message returned by the String method of
+// a Sentinel or a Template, which is by default the message given to Define
+// or the format given to DefineTemplate. Error is not changed.
+func DefineMessage(msg string) DefineOption {
+	return func(s *Sentinel) { s.public = msg }
+}
+
+// Sentinel is a sentinel error that is also the registered Coder of its code.
+//
+// It is meant to be declared once at the package level with Define, and to
+// be instantiated with New and Wrap, which record a stack trace:
+//
+//	var ErrNotFound = errors.Define(20404, "not found", errors.DefineHTTPStatus(404))
+//
+//	return ErrNotFound.Wrap(err)
+//
+// The errors returned by New and Wrap match the Sentinel with Is, and
+// ParseCoder returns the Sentinel for them.
+type Sentinel struct {
+	code   int
+	msg    string
+	status int
+	ref    string
+	ns     string
+	public string
+}
+
+// Define returns a Sentinel with the given code and message, and registers
+// it as the Coder of code. Like Register, Define panics if code is already
+// registered or reserved.
+func Define(code int, msg string, opts ...DefineOption) *Sentinel {
+	s := &Sentinel{
+		code:   code,
+		msg:    msg,
+		status: http.StatusInternalServerError,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	Register(s)
+	return s
+}
+
+// Error returns the message of the Sentinel.
+func (s *Sentinel) Error() string { return redact(s.msg) }
+
+func (s *Sentinel) plainMessage() string     { return s.msg }
+func (s *Sentinel) writePlain(out io.Writer) { _, _ = io.WriteString(out, s.msg) }
+
+// Code returns the code of the Sentinel.
+func (s *Sentinel) Code() int { return s.code }
+
+// String returns the user-facing message of the Sentinel, see DefineMessage.
+func (s *Sentinel) String() string {
+	if s.public != "" {
+		return s.public
+	}
+	return s.msg
+}
+
+// HTTPStatus returns the HTTP status of the Sentinel.
+func (s *Sentinel) HTTPStatus() int { return s.status }
+
+// Reference returns the reference document of the Sentinel.
+func (s *Sentinel) Reference() string { return s.ref }
+
+// Namespace returns the namespace of the Sentinel.
+func (s *Sentinel) Namespace() string { return s.ns }
+
+// New returns an instance of the Sentinel with a stack trace at the point
+// New is called.
+func (s *Sentinel) New() error {
+	return &withStack{
+		s,
+		callers(),
+		newOrigin(s),
+	}
+}
+
+// Wrap returns an error annotating err with the Sentinel and a stack trace
+// at the point Wrap is called. The error matches both err and the Sentinel
+// with Is, and its message is the message of the Sentinel followed by the
+// message of err.
+// If err is nil, Wrap returns nil.
+func (s *Sentinel) Wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	err = &withSentinel{
+		cause:    err,
+		sentinel: s,
+	}
+	return &withStack{
+		err,
+		callers(),
+		newOrigin(err),
+	}
+}
+
+type withSentinel struct {
+	cause    error
+	sentinel *Sentinel
+}
+
+func (w *withSentinel) Error() string { return redact(w.plainMessage()) }
+
+func (w *withSentinel) plainMessage() string {
+	return w.sentinel.msg + ": " + plainMessage(w.cause)
+}
+
+func (w *withSentinel) writePlain(out io.Writer) {
+	writePlain(out, w.cause)
+	_, _ = io.WriteString(out, "\n"+w.sentinel.msg)
+}
+
+func (w *withSentinel) Cause() error { return w.cause }
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (w *withSentinel) Unwrap() error { return w.cause }
+
+func (w *withSentinel) Code() int { return w.sentinel.code }
+
+// Is reports whether target is the Sentinel of w.
+func (w *withSentinel) Is(target error) bool {
+	s, ok := target.(*Sentinel)
+	return ok && s == w.sentinel
+}
+
+// As sets target to the Sentinel of w if target is a **Sentinel.
+func (w *withSentinel) As(target interface{}) bool {
+	if p, ok := target.(**Sentinel); ok {
+		*p = w.sentinel
+		return true
+	}
+	return false
+}
+
+func (w *withSentinel) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			formatPlus(s, w)
+			return
+		}
+		fallthrough
+	case 's', 'q':
+		_, _ = io.WriteString(s, w.Error())
+	}
+}
@@ -0,0 +1,140 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"testing"
+)
+
+func TestDefine(t *testing.T) {
+	s := Define(45001, "resource not found", DefineHTTPStatus(404), DefineReference("https://example.com/45001"))
+	defer Unregister(s)
+
+	if s.Code() != 45001 {
+		t.Errorf("code: want: %d, got: %d", 45001, s.Code())
+	}
+	if s.HTTPStatus() != 404 {
+		t.Errorf("http status: want: %d, got: %d", 404, s.HTTPStatus())
+	}
+	if s.Reference() != "https://example.com/45001" {
+		t.Errorf("reference: want: %s, got: %s", "https://example.com/45001", s.Reference())
+	}
+	if s.String() != "resource not found" || s.Error() != "resource not found" {
+		t.Errorf("message: want: %s, got: %s, %s", "resource not found", s.String(), s.Error())
+	}
+	if got := ParseCoder(s); got != Coder(s) {
+		t.Errorf("ParseCoder: want: %v, got: %v", s, got)
+	}
+
+	t.Run("default HTTP status", func(t *testing.T) {
+		s := Define(45002, "failed")
+		defer Unregister(s)
+		if s.HTTPStatus() != 500 {
+			t.Errorf("http status: want: %d, got: %d", 500, s.HTTPStatus())
+		}
+	})
+}
+
+func TestDefinePanic(t *testing.T) {
+	s := Define(45003, "failed")
+	defer Unregister(s)
+
+	defer func() {
+		r := recover()
+		want := "code `45003` already registered"
+		if r != want {
+			t.Errorf("recover: want: %v, got: %v", want, r)
+		}
+	}()
+	Define(45003, "failed again")
+}
+
+func TestSentinelNew(t *testing.T) {
+	s := Define(45004, "resource not found")
+	defer Unregister(s)
+
+	err := s.New()
+	if !Is(err, s) {
+		t.Errorf("Is: want: true, got: false")
+	}
+	if err.Error() != "resource not found" {
+		t.Errorf("Error: want: %s, got: %s", "resource not found", err.Error())
+	}
+	if got := ParseCoder(err); got != Coder(s) {
+		t.Errorf("ParseCoder: want: %v, got: %v", s, got)
+	}
+	want := "resource not found\n" +
+		"github.com/shipengqi/errors.TestSentinelNew\n" +
+		"\t.+/sentinel_test.go:58"
+	if got := fmt.Sprintf("%+v", err); !regexp.MustCompile(want).MatchString(got) {
+		t.Errorf("%%+v: want: %q, got: %q", want, got)
+	}
+}
+
+func TestSentinelWrap(t *testing.T) {
+	s := Define(45005, "resource not found")
+	defer Unregister(s)
+
+	if err := s.Wrap(nil); err != nil {
+		t.Errorf("Wrap(nil): want: nil, got: %v", err)
+	}
+
+	err := s.Wrap(io.EOF)
+	if !Is(err, s) {
+		t.Errorf("Is sentinel: want: true, got: false")
+	}
+	if !Is(err, io.EOF) {
+		t.Errorf("Is cause: want: true, got: false")
+	}
+	if Is(s.New(), io.EOF) {
+		t.Errorf("Is New: want: false, got: true")
+	}
+	if err.Error() != "resource not found: EOF" {
+		t.Errorf("Error: want: %s, got: %s", "resource not found: EOF", err.Error())
+	}
+	if got := ParseCoder(err); got != Coder(s) {
+		t.Errorf("ParseCoder: want: %v, got: %v", s, got)
+	}
+	var target *Sentinel
+	if !As(err, &target) || target != s {
+		t.Errorf("As: want: %v, got: %v", s, target)
+	}
+	want := "EOF\n" +
+		"resource not found\n" +
+		"github.com/shipengqi/errors.TestSentinelWrap\n" +
+		"\t.+/sentinel_test.go:84"
+	if got := fmt.Sprintf("%+v", err); !regexp.MustCompile(want).MatchString(got) {
+		t.Errorf("%%+v: want: %q, got: %q", want, got)
+	}
+
+	b, jerr := MarshalError(err)
+	if jerr != nil {
+		t.Fatalf("json.Marshal: %v", jerr)
+	}
+	var v struct {
+		Code int `json:"code"`
+	}
+	if jerr := json.Unmarshal(b, &v); jerr != nil {
+		t.Fatalf("json.Unmarshal: %v", jerr)
+	}
+	if v.Code != 45005 {
+		t.Errorf("json code: want: %d, got: %d (%s)", 45005, v.Code, b)
+	}
+}
+
+func TestDefineMessage(t *testing.T) {
+	s := Define(45003, "row 42 locked by txn 7", DefineMessage("Try again later"))
+	defer Unregister(s)
+
+	if s.String() != "Try again later" {
+		t.Errorf("string: want: %s, got: %s", "Try again later", s.String())
+	}
+	if s.Error() != "row 42 locked by txn 7" {
+		t.Errorf("error: want: %s, got: %s", "row 42 locked by txn 7", s.Error())
+	}
+	if got := PublicMessage(s.New()); got != "Try again later" {
+		t.Errorf("PublicMessage: want: %s, got: %s", "Try again later", got)
+	}
+}
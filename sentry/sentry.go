@@ -0,0 +1,369 @@
+// Package sentry builds Sentry events from the errors of
+// github.com/shipengqi/errors, without depending on the Sentry SDK.
+//
+// An Exporter turns an error chain into an Event, with an Exception for each
+// error of the chain and the frames of the stack traces recorded by New, Wrap
+// and the like. Events are sent through a Transport; WriterTransport writes
+// them as Sentry envelopes to an io.Writer.
+package sentry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shipengqi/errors"
+	"github.com/shipengqi/errors/internal/chain"
+)
+
+// Level is the level of an Event.
+type Level string
+
+// Levels of an Event, see LevelOf.
+const (
+	LevelDebug   Level = "debug"
+	LevelInfo    Level = "info"
+	LevelWarning Level = "warning"
+	LevelError   Level = "error"
+	LevelFatal   Level = "fatal"
+)
+
+// LevelOf returns the Level matching errors.SeverityOf(err).
+func LevelOf(err error) Level {
+	switch errors.SeverityOf(err) {
+	case errors.SeverityDebug:
+		return LevelDebug
+	case errors.SeverityInfo:
+		return LevelInfo
+	case errors.SeverityWarn:
+		return LevelWarning
+	case errors.SeverityCritical:
+		return LevelFatal
+	default:
+		return LevelError
+	}
+}
+
+// Event is a Sentry error event.
+type Event struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   time.Time              `json:"timestamp"`
+	Platform    string                 `json:"platform"`
+	Level       Level                  `json:"level"`
+	Release     string                 `json:"release,omitempty"`
+	Environment string                 `json:"environment,omitempty"`
+	ServerName  string                 `json:"server_name,omitempty"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+	Fingerprint []string               `json:"fingerprint,omitempty"`
+	Exception   []Exception            `json:"exception,omitempty"`
+}
+
+// Exception is an error of the chain of an Event.
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Module     string      `json:"module,omitempty"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+	Mechanism  *Mechanism  `json:"mechanism,omitempty"`
+}
+
+// Mechanism tells how an Exception relates to the others of its Event.
+// The Exceptions of the members of an errors.Aggregate have the ID of the
+// Exception of the aggregate as parent.
+type Mechanism struct {
+	Type             string `json:"type"`
+	Source           string `json:"source,omitempty"`
+	ExceptionID      int    `json:"exception_id"`
+	ParentID         *int   `json:"parent_id,omitempty"`
+	IsExceptionGroup bool   `json:"is_exception_group,omitempty"`
+}
+
+// Stacktrace holds the frames of an Exception, the oldest call first.
+type Stacktrace struct {
+	Frames []Frame `json:"frames"`
+}
+
+// Frame is a frame of a Stacktrace.
+type Frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	AbsPath  string `json:"abs_path,omitempty"`
+	Lineno   int    `json:"lineno,omitempty"`
+	InApp    bool   `json:"in_app"`
+}
+
+// Transport sends Events.
+type Transport interface {
+	Send(event *Event) error
+}
+
+// TransportFunc is an adapter to allow the use of ordinary functions as
+// Transport.
+type TransportFunc func(event *Event) error
+
+// Send calls f(event).
+func (f TransportFunc) Send(event *Event) error { return f(event) }
+
+// WriterTransport returns a Transport writing each Event as an envelope to w,
+// see WriteEnvelope. It is safe for concurrent use.
+func WriterTransport(w io.Writer) Transport {
+	var mu sync.Mutex
+	return TransportFunc(func(event *Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return WriteEnvelope(w, event)
+	})
+}
+
+// WriteEnvelope writes event to w in the Sentry envelope format: an envelope
+// header, an item header and the JSON event, each on its own line.
+func WriteEnvelope(w io.Writer, event *Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "sentry: marshal event")
+	}
+	header, _ := json.Marshal(struct {
+		EventID string `json:"event_id"`
+	}{event.EventID})
+	item, _ := json.Marshal(struct {
+		Type   string `json:"type"`
+		Length int    `json:"length"`
+	}{"event", len(payload)})
+
+	_, err = fmt.Fprintf(w, "%s\n%s\n%s\n", header, item, payload)
+	return err
+}
+
+// maxExceptions bounds the number of Exceptions of an Event, so that a cyclic
+// chain cannot loop forever.
+const maxExceptions = 100
+
+// Exporter builds Events from errors. The zero Exporter is ready to use.
+type Exporter struct {
+	// InAppPrefixes are the module paths of the application. A frame is in-app
+	// when its package is one of them or below one of them. If empty, every
+	// frame outside the standard library is in-app.
+	InAppPrefixes []string
+
+	Release     string
+	Environment string
+	ServerName  string
+
+	// Now returns the timestamp of an Event whose error has no creation time
+	// recorded. It defaults to time.Now.
+	Now func() time.Time
+
+	// NewID returns the ID of an Event. It defaults to errors.RandomID.
+	NewID func() string
+
+	// Transport sends the Events of Capture.
+	Transport Transport
+}
+
+// Capture builds the Event of err and sends it with the Transport of e.
+// It returns the ID of the Event.
+func (e *Exporter) Capture(err error) (string, error) {
+	if err == nil {
+		return "", nil
+	}
+	if e.Transport == nil {
+		return "", errors.New("sentry: no transport")
+	}
+	event := e.Event(err)
+	if err := e.Transport.Send(event); err != nil {
+		return "", errors.Wrap(err, "sentry: send event")
+	}
+	return event.EventID, nil
+}
+
+// Event returns the Event of err, or nil if err is nil.
+//
+// The Exceptions are ordered as Sentry expects, the root cause first. The Event
+// is tagged with the code and HTTP status of errors.ParseCoder(err), the
+// instance ID of err, and its errors.FieldsOf. Its level is LevelOf(err) and
+// its fingerprint is errors.Fingerprint(err). The values come from Error and
+// fmt, so they go through the Redactor set with errors.SetRedactor.
+func (e *Exporter) Event(err error) *Event {
+	if err == nil {
+		return nil
+	}
+	coder := errors.ParseCoder(err)
+	event := &Event{
+		EventID:     e.newID(),
+		Timestamp:   errors.Timestamp(err),
+		Platform:    "go",
+		Level:       LevelOf(err),
+		Release:     e.Release,
+		Environment: e.Environment,
+		ServerName:  e.ServerName,
+		Tags: map[string]string{
+			"code":        strconv.Itoa(coder.Code()),
+			"http_status": strconv.Itoa(coder.HTTPStatus()),
+		},
+		Fingerprint: []string{errors.Fingerprint(err)},
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = e.now()
+	}
+	event.Timestamp = event.Timestamp.UTC()
+	if id := errors.ID(err); id != "" {
+		event.Tags["error_id"] = id
+	}
+	for k, v := range errors.FieldsOf(err) {
+		event.Tags[k] = errors.Redact(fmt.Sprint(v))
+	}
+	if ref := coder.Reference(); ref != "" {
+		event.Extra = map[string]interface{}{"reference": ref}
+	}
+
+	b := builder{exporter: e}
+	b.chain(err, "", -1)
+	if len(b.exceptions) == 1 {
+		b.exceptions[0].Mechanism = nil
+	}
+	// Sentry expects the oldest exception first.
+	for i, j := 0, len(b.exceptions)-1; i < j; i, j = i+1, j-1 {
+		b.exceptions[i], b.exceptions[j] = b.exceptions[j], b.exceptions[i]
+	}
+	event.Exception = b.exceptions
+	return event
+}
+
+func (e *Exporter) now() time.Time {
+	if e.Now != nil {
+		return e.Now()
+	}
+	return time.Now()
+}
+
+func (e *Exporter) newID() string {
+	if e.NewID != nil {
+		return e.NewID()
+	}
+	return errors.RandomID()
+}
+
+// inApp reports whether the frames of the package module are in-app.
+func (e *Exporter) inApp(module string) bool {
+	if len(e.InAppPrefixes) == 0 {
+		first := module
+		if i := strings.Index(first, "/"); i >= 0 {
+			first = first[:i]
+		}
+		return module == "main" || strings.Contains(first, ".")
+	}
+	for _, prefix := range e.InAppPrefixes {
+		if module == prefix || strings.HasPrefix(module, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// builder collects the Exceptions of an Event, the outermost error first.
+type builder struct {
+	exporter   *Exporter
+	exceptions []Exception
+}
+
+// chain adds the Exceptions of err and of its causes. The Exception of err
+// has the given source and parent, -1 meaning none.
+func (b *builder) chain(err error, source string, parent int) {
+	for err != nil && len(b.exceptions) < maxExceptions {
+		id := len(b.exceptions)
+		exc := b.exception(err)
+		exc.Mechanism = &Mechanism{Type: "chained", Source: source, ExceptionID: id}
+		if id == 0 {
+			exc.Mechanism.Type = "generic"
+		}
+		if parent >= 0 {
+			p := parent
+			exc.Mechanism.ParentID = &p
+		}
+		b.exceptions = append(b.exceptions, exc)
+
+		if errs, ok := members(err); ok {
+			exc.Mechanism.IsExceptionGroup = true
+			for i, member := range errs {
+				b.chain(member, fmt.Sprintf("errors[%d]", i), id)
+			}
+			return
+		}
+		err, source, parent = chain.Next(err), "cause", id
+	}
+}
+
+// exception returns the Exception of err alone, without its causes.
+func (b *builder) exception(err error) Exception {
+	t := reflect.TypeOf(err)
+	exc := Exception{Type: t.String(), Value: err.Error()}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	exc.Module = t.PkgPath()
+	if v, ok := err.(interface{ StackTrace() errors.StackTrace }); ok {
+		if frames := b.frames(v.StackTrace()); len(frames) > 0 {
+			exc.Stacktrace = &Stacktrace{Frames: frames}
+		}
+	}
+	return exc
+}
+
+// frames returns the frames of st, the oldest call first, without the frames
+// of the runtime and testing packages.
+func (b *builder) frames(st errors.StackTrace) []Frame {
+	frames := make([]Frame, 0, len(st))
+	for i := len(st) - 1; i >= 0; i-- {
+		pc := uintptr(st[i]) - 1
+		fn := runtime.FuncForPC(pc)
+		if fn == nil {
+			continue
+		}
+		file, line := fn.FileLine(pc)
+		module, function := splitFuncName(fn.Name())
+		if module == "runtime" || module == "testing" {
+			continue
+		}
+		frames = append(frames, Frame{
+			Function: function,
+			Module:   module,
+			Filename: path.Base(file),
+			AbsPath:  file,
+			Lineno:   line,
+			InApp:    b.exporter.inApp(module),
+		})
+	}
+	return frames
+}
+
+// splitFuncName splits the name of a function into its package path and its
+// name within the package, for example "github.com/a/b.(*T).M" into
+// "github.com/a/b" and "(*T).M".
+func splitFuncName(name string) (module, function string) {
+	slash := strings.LastIndex(name, "/")
+	if i := strings.Index(name[slash+1:], "."); i >= 0 {
+		return name[:slash+1+i], name[slash+1+i+1:]
+	}
+	return "", name
+}
+
+// members returns the errors grouped by err, if it is an errors.Aggregate or
+// a Go 1.20 multiple error.
+func members(err error) ([]error, bool) {
+	switch x := err.(type) {
+	case errors.Aggregate:
+		return x.Errors(), true
+	case interface{ Unwrap() []error }:
+		return x.Unwrap(), true
+	}
+	return nil, false
+}
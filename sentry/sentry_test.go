@@ -0,0 +1,205 @@
+package sentry
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shipengqi/errors"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+type notFoundCoder struct{}
+
+func (notFoundCoder) Code() int         { return 40401 }
+func (notFoundCoder) HTTPStatus() int   { return 404 }
+func (notFoundCoder) String() string    { return "User not found" }
+func (notFoundCoder) Reference() string { return "https://example.com/errors/40401" }
+
+func init() {
+	errors.Register(notFoundCoder{})
+}
+
+var testExporter = &Exporter{
+	InAppPrefixes: []string{"github.com/shipengqi/errors/sentry"},
+	Release:       "v1.2.3",
+	Environment:   "test",
+	Now:           func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) },
+	NewID:         func() string { return "0123456789abcdef0123456789abcdef" },
+}
+
+// moduleRoot is the directory of the module, trimmed from the paths of the
+// frames so that the golden files do not depend on the checkout location.
+var moduleRoot = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.ToSlash(filepath.Dir(filepath.Dir(file))) + "/"
+}()
+
+func normalize(event *Event) {
+	for _, exc := range event.Exception {
+		if exc.Stacktrace == nil {
+			continue
+		}
+		for i := range exc.Stacktrace.Frames {
+			f := &exc.Stacktrace.Frames[i]
+			f.AbsPath = strings.TrimPrefix(f.AbsPath, moduleRoot)
+		}
+	}
+}
+
+func findUser() error {
+	err := errors.Wrap(errors.New("no rows in result set"), "find user")
+	err = errors.WithCode(err, 40401)
+	err = errors.WithSeverity(err, errors.SeverityWarn)
+	return errors.WithFields(err, errors.Fields{"user": 42})
+}
+
+func importAll() error {
+	return errors.NewAggregate([]error{
+		errors.New("invalid row 3"),
+		errors.Wrap(io.ErrUnexpectedEOF, "read row 7"),
+	})
+}
+
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	file := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(file, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s: want:\n%s\ngot:\n%s", file, want, got)
+	}
+}
+
+func TestExporterEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"wrapped", findUser()},
+		{"aggregate", importAll()},
+		{"foreign", fmt.Errorf("open config: %w", os.ErrNotExist)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := testExporter.Event(tt.err)
+			normalize(event)
+			got, err := json.MarshalIndent(event, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden(t, tt.name, append(got, '\n'))
+		})
+	}
+}
+
+func TestExporterEventNil(t *testing.T) {
+	if got := testExporter.Event(nil); got != nil {
+		t.Errorf("want nil, got %+v", got)
+	}
+}
+
+func TestExporterCapture(t *testing.T) {
+	var buf bytes.Buffer
+	e := *testExporter
+	e.Transport = TransportFunc(func(event *Event) error {
+		normalize(event)
+		return WriteEnvelope(&buf, event)
+	})
+	id, err := e.Capture(findUser())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "0123456789abcdef0123456789abcdef" {
+		t.Errorf("want ID %q, got %q", "0123456789abcdef0123456789abcdef", id)
+	}
+	golden(t, "envelope", buf.Bytes())
+}
+
+func TestExporterCaptureErrors(t *testing.T) {
+	e := *testExporter
+	if _, err := e.Capture(errors.New("boom")); err == nil {
+		t.Errorf("want an error without transport, got nil")
+	}
+	e.Transport = TransportFunc(func(*Event) error { return io.ErrClosedPipe })
+	if _, err := e.Capture(errors.New("boom")); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("want %v, got %v", io.ErrClosedPipe, err)
+	}
+}
+
+func TestWriterTransport(t *testing.T) {
+	var buf bytes.Buffer
+	event := &Event{EventID: "abc", Platform: "go", Level: LevelError}
+	if err := WriterTransport(&buf).Send(event); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("want 3 lines, got %d: %q", len(lines), buf.String())
+	}
+	if want := `{"event_id":"abc"}`; lines[0] != want {
+		t.Errorf("envelope header: want %s, got %s", want, lines[0])
+	}
+	if want := fmt.Sprintf(`{"type":"event","length":%d}`, len(lines[2])); lines[1] != want {
+		t.Errorf("item header: want %s, got %s", want, lines[1])
+	}
+}
+
+func TestLevelOf(t *testing.T) {
+	tests := []struct {
+		err  error
+		want Level
+	}{
+		{errors.New("boom"), LevelError},
+		{errors.WithSeverity(errors.New("boom"), errors.SeverityDebug), LevelDebug},
+		{errors.WithSeverity(errors.New("boom"), errors.SeverityInfo), LevelInfo},
+		{errors.WithSeverity(errors.New("boom"), errors.SeverityWarn), LevelWarning},
+		{errors.WithSeverity(errors.New("boom"), errors.SeverityCritical), LevelFatal},
+	}
+
+	for _, tt := range tests {
+		if got := LevelOf(tt.err); got != tt.want {
+			t.Errorf("LevelOf(%v): want %s, got %s", tt.err, tt.want, got)
+		}
+	}
+}
+
+func TestExporterInApp(t *testing.T) {
+	tests := []struct {
+		prefixes []string
+		module   string
+		want     bool
+	}{
+		{nil, "github.com/a/b", true},
+		{nil, "main", true},
+		{nil, "net/http", false},
+		{[]string{"github.com/a/b"}, "github.com/a/b", true},
+		{[]string{"github.com/a/b"}, "github.com/a/b/c", true},
+		{[]string{"github.com/a/b"}, "github.com/a/bc", false},
+		{[]string{"github.com/a/b"}, "github.com/x/y", false},
+	}
+
+	for _, tt := range tests {
+		e := &Exporter{InAppPrefixes: tt.prefixes}
+		if got := e.inApp(tt.module); got != tt.want {
+			t.Errorf("inApp(%q) with %v: want %t, got %t", tt.module, tt.prefixes, tt.want, got)
+		}
+	}
+}
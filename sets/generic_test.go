@@ -0,0 +1,113 @@
+//go:build go1.18
+// +build go1.18
+
+package sets
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	s := Set[int]{}
+	s2 := Set[int]{}
+	if len(s) != 0 {
+		t.Errorf("Expected len=0: %d", len(s))
+	}
+	s.Insert(1, 2)
+	if len(s) != 2 {
+		t.Errorf("Expected len=2: %d", len(s))
+	}
+	s.Insert(3)
+	if s.Has(4) {
+		t.Errorf("Unexpected contents: %#v", s)
+	}
+	if !s.Has(1) {
+		t.Errorf("Missing contents: %#v", s)
+	}
+	s.Delete(1)
+	if s.Has(1) {
+		t.Errorf("Unexpected contents: %#v", s)
+	}
+	s.Insert(1)
+	if s.HasAll(1, 2, 4) {
+		t.Errorf("Unexpected contents: %#v", s)
+	}
+	if !s.HasAll(1, 2) {
+		t.Errorf("Missing contents: %#v", s)
+	}
+	if !s.HasAny(1, 4) || s.HasAny(0, 4) {
+		t.Errorf("Unexpected HasAny result: %#v", s)
+	}
+	s2.Insert(1, 2, 4)
+	if s.IsSuperset(s2) {
+		t.Errorf("Unexpected contents: %#v", s)
+	}
+	s2.Delete(4)
+	if !s.IsSuperset(s2) {
+		t.Errorf("Missing contents: %#v", s)
+	}
+}
+
+func TestKeySet(t *testing.T) {
+	s := KeySet(map[int]string{
+		1: "",
+		2: "value2",
+	})
+	if !s.Equal(New(1, 2)) {
+		t.Errorf("Unexpected contents: %#v", s)
+	}
+}
+
+func TestSetPopAny(t *testing.T) {
+	s := New(10)
+	popped, ok := s.PopAny()
+	if !ok || popped != 10 {
+		t.Errorf("Expected 10, got %d", popped)
+	}
+	popped, ok = s.PopAny()
+	if ok || popped != 0 {
+		t.Errorf("Expected zero value, got %d", popped)
+	}
+}
+
+func TestSetList(t *testing.T) {
+	s := New(3, 1, 20, 2)
+	if got := s.List(func(a, b int) bool { return a < b }); !reflect.DeepEqual(got, []int{1, 2, 3, 20}) {
+		t.Errorf("List gave unexpected result: %#v", got)
+	}
+	if got := s.List(func(a, b int) bool { return a > b }); !reflect.DeepEqual(got, []int{20, 3, 2, 1}) {
+		t.Errorf("List gave unexpected result: %#v", got)
+	}
+	if got := s.UnsortedList(); len(got) != 4 {
+		t.Errorf("Expected len=4: %d", len(got))
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	a := New(1, 2, 3, 4)
+	b := New(3, 4, 5, 6)
+	if got := a.Union(b); !got.Equal(New(1, 2, 3, 4, 5, 6)) {
+		t.Errorf("Unexpected union: %v", got)
+	}
+	if got := a.Intersection(b); !got.Equal(New(3, 4)) {
+		t.Errorf("Unexpected intersection: %v", got)
+	}
+	if got := a.Difference(b); !got.Equal(New(1, 2)) {
+		t.Errorf("Unexpected difference: %v", got)
+	}
+	if got := New[int]().Union(New[int]()); got.Len() != 0 {
+		t.Errorf("Unexpected union: %v", got)
+	}
+}
+
+func TestSetStringConversion(t *testing.T) {
+	s := Set[string](NewString("a", "b"))
+	if !s.Equal(New("a", "b")) {
+		t.Errorf("Unexpected contents: %#v", s)
+	}
+	str := String(New("z", "y"))
+	if !reflect.DeepEqual(str.List(), []string{"y", "z"}) {
+		t.Errorf("List gave unexpected result: %#v", str.List())
+	}
+}
@@ -6,6 +6,7 @@ import (
 )
 
 // String is a set of strings, implemented via map[string]struct{} for minimal memory consumption.
+// On Go 1.18 and later, String and Set[string] can be converted to each other.
 type String map[string]Empty
 
 // NewString creates a String from a list of values.
@@ -17,6 +18,7 @@ func NewString(items ...string) String {
 
 // StringKeySet creates a String from a keys of a map[string](? extends interface{}).
 // If the value passed in is not actually a map, this will panic.
+// On Go 1.18 and later, KeySet builds a Set from a map without reflection.
 func StringKeySet(theMap interface{}) String {
 	v := reflect.ValueOf(theMap)
 	ret := String{}
@@ -0,0 +1,155 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Severity is the level of attention an error requires.
+type Severity int
+
+const (
+	// SeverityDebug is for errors that are only of interest when debugging.
+	SeverityDebug Severity = iota
+	// SeverityInfo is for expected errors that need no action.
+	SeverityInfo
+	// SeverityWarn is for errors that may need action if they persist.
+	SeverityWarn
+	// SeverityError is for errors that need action. It is the severity of
+	// errors that do not declare one.
+	SeverityError
+	// SeverityCritical is for errors that need immediate action.
+	SeverityCritical
+)
+
+var severityNames = []string{"debug", "info", "warn", "error", "critical"}
+
+func (s Severity) String() string {
+	if s < SeverityDebug || s > SeverityCritical {
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+	return severityNames[s]
+}
+
+// MarshalText encodes the severity as its name.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a severity from its name.
+func (s *Severity) UnmarshalText(text []byte) error {
+	name := strings.ToLower(string(text))
+	for i, v := range severityNames {
+		if v == name {
+			*s = Severity(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown severity %q", text)
+}
+
+type severitier interface {
+	Severity() Severity
+}
+
+// WithSeverity annotates err with a severity.
+// If err is nil, WithSeverity returns nil.
+func WithSeverity(err error, severity Severity) error {
+	if err == nil {
+		return nil
+	}
+	return &withSeverity{
+		cause:    err,
+		severity: severity,
+	}
+}
+
+type withSeverity struct {
+	cause    error
+	severity Severity
+}
+
+func (w *withSeverity) Error() string      { return redact(plainMessage(w.cause)) }
+func (w *withSeverity) Cause() error       { return w.cause }
+func (w *withSeverity) Severity() Severity { return w.severity }
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (w *withSeverity) Unwrap() error { return w.cause }
+
+func (w *withSeverity) plainMessage() string { return plainMessage(w.cause) }
+
+func (w *withSeverity) writePlain(out io.Writer) {
+	writePlain(out, w.cause)
+	_, _ = fmt.Fprintf(out, "\nseverity: %s", w.severity)
+}
+
+func (w *withSeverity) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			formatPlus(s, w)
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(s, w.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", w.Error())
+	}
+}
+
+// SeverityOf returns the highest severity found in err's chain.
+//
+// The severity is declared by the errors of the chain, or by their
+// registered Coders, that implement the following interface:
+//
+//	type severitier interface {
+//	        Severity() Severity
+//	}
+//
+// If the chain contains an Aggregate, the severity of each of its errors is
+// considered. SeverityOf returns SeverityError if nothing declares a
+// severity, and SeverityDebug if err is nil.
+func SeverityOf(err error) Severity {
+	if err == nil {
+		return SeverityDebug
+	}
+	if severity, ok := declaredSeverity(err); ok {
+		return severity
+	}
+	return SeverityError
+}
+
+// declaredSeverity returns the highest severity declared in err's chain.
+func declaredSeverity(err error) (Severity, bool) {
+	var (
+		highest Severity
+		found   bool
+	)
+	declare := func(severity Severity) {
+		if !found || severity > highest {
+			highest = severity
+			found = true
+		}
+	}
+	walk(err, func(err error) bool {
+		if v, ok := err.(severitier); ok {
+			declare(v.Severity())
+		}
+		if v, ok := err.(icoder); ok {
+			coder, _ := Lookup(v.Code())
+			if s, ok := coder.(severitier); ok {
+				declare(s.Severity())
+			}
+		}
+		if agg, ok := err.(Aggregate); ok {
+			aggregate(agg.Errors()).visit(func(err error) bool {
+				declare(SeverityOf(err))
+				return false
+			})
+		}
+		return false
+	})
+	return highest, found
+}
@@ -0,0 +1,104 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"testing"
+)
+
+type severityCoder struct {
+	defaultCoder
+	severity Severity
+}
+
+func (c severityCoder) Severity() Severity { return c.severity }
+
+func TestSeverityString(t *testing.T) {
+	tests := []struct {
+		severity Severity
+		want     string
+	}{
+		{SeverityDebug, "debug"},
+		{SeverityInfo, "info"},
+		{SeverityWarn, "warn"},
+		{SeverityError, "error"},
+		{SeverityCritical, "critical"},
+		{Severity(42), "Severity(42)"},
+	}
+	for _, tt := range tests {
+		if got := tt.severity.String(); got != tt.want {
+			t.Errorf("String(): want: %q, got: %q", tt.want, got)
+		}
+	}
+}
+
+func TestSeverityUnmarshalText(t *testing.T) {
+	var s Severity
+	if err := s.UnmarshalText([]byte("Critical")); err != nil || s != SeverityCritical {
+		t.Errorf("UnmarshalText: want: %v, got: %v (%v)", SeverityCritical, s, err)
+	}
+	if err := s.UnmarshalText([]byte("fatal")); err == nil {
+		t.Error("UnmarshalText: expected an error")
+	}
+}
+
+func TestSeverityOf(t *testing.T) {
+	codes := []Coder{
+		severityCoder{defaultCoder{code: 20001, status: 503, msg: "unavailable"}, SeverityCritical},
+		severityCoder{defaultCoder{code: 20002, status: 404, msg: "not found"}, SeverityInfo},
+	}
+	for _, v := range codes {
+		Register(v)
+	}
+	defer func() {
+		for _, v := range codes {
+			Unregister(v)
+		}
+	}()
+
+	runs := []struct {
+		expected Severity
+		err      error
+	}{
+		{SeverityDebug, nil},
+		{SeverityError, io.EOF},
+		{SeverityWarn, WithSeverity(io.EOF, SeverityWarn)},
+		{SeverityWarn, Wrap(WithSeverity(io.EOF, SeverityWarn), "read")},
+		{SeverityWarn, fmt.Errorf("read: %w", WithSeverity(io.EOF, SeverityWarn))},
+		{SeverityCritical, WithSeverity(WithSeverity(io.EOF, SeverityCritical), SeverityDebug)},
+		{SeverityCritical, WithCode(io.EOF, 20001)},
+		{SeverityInfo, WithCode(io.EOF, 20002)},
+		{SeverityCritical, WithSeverity(WithCode(io.EOF, 20001), SeverityWarn)},
+		{SeverityError, WithCode(io.EOF, 99999)},
+		{SeverityWarn, NewAggregate([]error{WithSeverity(io.EOF, SeverityInfo), WithSeverity(io.EOF, SeverityWarn)})},
+		{SeverityError, NewAggregate([]error{WithSeverity(io.EOF, SeverityInfo), io.EOF})},
+		{SeverityCritical, Wrap(NewAggregate([]error{io.EOF, NewAggregate([]error{WithCode(io.EOF, 20001)})}), "batch")},
+	}
+	for i, r := range runs {
+		if got := SeverityOf(r.err); got != r.expected {
+			t.Errorf("%d: SeverityOf: want: %v, got: %v", i, r.expected, got)
+		}
+	}
+}
+
+func TestWithSeverityNil(t *testing.T) {
+	if got := WithSeverity(nil, SeverityWarn); got != nil {
+		t.Errorf("WithSeverity(nil, SeverityWarn): got %#v, expected nil", got)
+	}
+}
+
+func TestFormatWithSeverity(t *testing.T) {
+	err := WithSeverity(io.EOF, SeverityWarn)
+	for format, want := range map[string]string{"%s": "EOF", "%v": "EOF", "%q": `"EOF"`, "%+v": "EOF\nseverity: warn"} {
+		if got := fmt.Sprintf(format, err); got != want {
+			t.Errorf("Sprintf(%q): want: %q, got: %q", format, want, got)
+		}
+	}
+
+	got := fmt.Sprintf("%+v", WithSeverity(New("error"), SeverityCritical))
+	want := "(?s)^error\n.+\nseverity: critical$"
+	if !regexp.MustCompile(want).MatchString(got) {
+		t.Errorf("Sprintf(%%+v):\n got: %q\nwant: %q", got, want)
+	}
+}
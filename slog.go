@@ -0,0 +1,70 @@
+//go:build go1.21
+
+package errors
+
+import (
+	"log/slog"
+	"sort"
+)
+
+// logValue returns the slog representation of err: a group with its
+// message, instance ID, code, severity and fields.
+func logValue(err error) slog.Value {
+	attrs := []slog.Attr{slog.String("msg", redact(plainMessage(err)))}
+	if id := ID(err); id != "" {
+		attrs = append(attrs, slog.String("id", id))
+	}
+	attrs = append(attrs,
+		slog.Int("code", coderOf(err).Code()),
+		slog.String("severity", SeverityOf(err).String()),
+	)
+	if fields := redactFields(FieldsOf(err)); len(fields) > 0 {
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fieldAttrs := make([]any, 0, len(keys))
+		for _, k := range keys {
+			fieldAttrs = append(fieldAttrs, slog.Any(k, fields[k]))
+		}
+		attrs = append(attrs, slog.Group("fields", fieldAttrs...))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements slog.LogValuer.
+func (f *fundamental) LogValue() slog.Value { return logValue(f) }
+
+// LogValue implements slog.LogValuer.
+func (w *withStack) LogValue() slog.Value { return logValue(w) }
+
+// LogValue implements slog.LogValuer.
+func (w *withMessage) LogValue() slog.Value { return logValue(w) }
+
+// LogValue implements slog.LogValuer.
+func (w *withCode) LogValue() slog.Value { return logValue(w) }
+
+// LogValue implements slog.LogValuer.
+func (w *withSentinel) LogValue() slog.Value { return logValue(w) }
+
+// LogValue implements slog.LogValuer.
+func (w *withTemplate) LogValue() slog.Value { return logValue(w) }
+
+// LogValue implements slog.LogValuer.
+func (w *withRetryable) LogValue() slog.Value { return logValue(w) }
+
+// LogValue implements slog.LogValuer.
+func (w *withSeverity) LogValue() slog.Value { return logValue(w) }
+
+// LogValue implements slog.LogValuer.
+func (w *withFields) LogValue() slog.Value { return logValue(w) }
+
+// LogValue implements slog.LogValuer.
+func (w *withComponent) LogValue() slog.Value { return logValue(w) }
+
+// LogValue implements slog.LogValuer.
+func (w *withPublicMessage) LogValue() slog.Value { return logValue(w) }
+
+// LogValue implements slog.LogValuer.
+func (agg aggregate) LogValue() slog.Value { return logValue(agg) }
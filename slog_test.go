@@ -0,0 +1,54 @@
+//go:build go1.21
+// +build go1.21
+
+package errors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestLogValue(t *testing.T) {
+	withExtractors(t, ContextValue("request_id", contextKey("request")))
+	ctx := context.WithValue(context.Background(), contextKey("request"), "req-1")
+
+	mockCode := defaultCoder{code: 20001, status: 404, msg: "not found"}
+	Register(mockCode)
+	defer Unregister(mockCode)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Error("request failed", "err", WithCode(WrapCtx(ctx, io.EOF, "read"), 20001))
+
+	var v struct {
+		Err struct {
+			Msg      string            `json:"msg"`
+			Code     int               `json:"code"`
+			Severity string            `json:"severity"`
+			Fields   map[string]string `json:"fields"`
+		} `json:"err"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Err.Msg != "code: 20001, read: EOF" || v.Err.Code != 20001 || v.Err.Severity != "error" ||
+		v.Err.Fields["request_id"] != "req-1" {
+		t.Errorf("LogValue: unexpected %s", buf.Bytes())
+	}
+}
+
+func TestLogValueRedacted(t *testing.T) {
+	SetRedactor(RedactEmails)
+	defer SetRedactor(nil)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	logger.Error("failed", "err", WithFields(New("user alice@example.com"), Fields{"email": "bob@example.com"}))
+	if bytes.Contains(buf.Bytes(), []byte("@example.com")) {
+		t.Errorf("LogValue leaked a secret: %s", buf.Bytes())
+	}
+}
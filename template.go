@@ -0,0 +1,202 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// Template is a kind of error whose message is formatted from parameters,
+// and that is also the registered Coder of its code.
+//
+// It is meant to be declared once at the package level with DefineTemplate,
+// and to be instantiated with New:
+//
+//	var ErrUserNotFound = errors.DefineTemplate("user_not_found", 20404,
+//		"user %s not found in tenant %d", []string{"user", "tenant"})
+//
+//	return ErrUserNotFound.New(name, tenant)
+//
+// The errors returned by New match the Template with Is, carry the arguments
+// as Fields keyed by the names of the parameters, and ParseCoder returns the
+// Template for them.
+type Template struct {
+	id     string
+	params []string
+	coder  Sentinel
+}
+
+// DefineTemplate returns a Template with the given ID, code, format and names
+// of the parameters of the format, and registers it as the Coder of code.
+// The options are the ones of Define.
+// DefineTemplate panics if the format has not one verb per parameter, or if
+// it uses explicit argument indexes or * widths. Like Register, it panics if
+// code is already registered or reserved.
+func DefineTemplate(id string, code int, format string, params []string, opts ...DefineOption) *Template {
+	verbs, err := templateVerbs(format)
+	if err != nil {
+		panic(fmt.Sprintf("template `%s`: %v", id, err))
+	}
+	if len(verbs) != len(params) {
+		panic(fmt.Sprintf("template `%s`: format has %d verbs but %d parameters", id, len(verbs), len(params)))
+	}
+	t := &Template{
+		id:     id,
+		params: params,
+		coder: Sentinel{
+			code:   code,
+			msg:    format,
+			status: http.StatusInternalServerError,
+		},
+	}
+	for _, opt := range opts {
+		opt(&t.coder)
+	}
+	Register(t)
+	return t
+}
+
+// ID returns the ID of the Template.
+func (t *Template) ID() string { return t.id }
+
+// Params returns the names of the parameters of the Template.
+func (t *Template) Params() []string { return t.params }
+
+// Error returns the format of the Template.
+func (t *Template) Error() string { return t.coder.msg }
+
+// Code returns the code of the Template.
+func (t *Template) Code() int { return t.coder.code }
+
+// String returns the user-facing message of the Template set with
+// DefineMessage, or its format if there is none.
+func (t *Template) String() string { return t.coder.String() }
+
+// HTTPStatus returns the HTTP status of the Template.
+func (t *Template) HTTPStatus() int { return t.coder.status }
+
+// Reference returns the reference document of the Template.
+func (t *Template) Reference() string { return t.coder.ref }
+
+// Namespace returns the namespace of the Template.
+func (t *Template) Namespace() string { return t.coder.ns }
+
+// New returns an instance of the Template formatted with args, with a stack
+// trace at the point New is called. The arguments are kept as Fields keyed by
+// the names of the parameters.
+// Like fmt.Sprintf, New reports a missing or mismatched argument in the
+// message, as %!d(MISSING) or %!d(string=x), instead of failing.
+func (t *Template) New(args ...interface{}) error {
+	fields := make(Fields, len(args))
+	for i, arg := range args {
+		if i == len(t.params) {
+			break
+		}
+		fields[t.params[i]] = arg
+	}
+	err := &withTemplate{
+		template: t,
+		args:     args,
+		fields:   fields,
+	}
+	return &withStack{
+		err,
+		callers(),
+		newOrigin(err),
+	}
+}
+
+// templateVerbs returns the verbs of format, with their flags, width and
+// precision, as "%-5d".
+func templateVerbs(format string) ([]string, error) {
+	var verbs []string
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		j := i + 1
+		for j < len(format) && strings.IndexByte("+-# 0123456789.*[]", format[j]) >= 0 {
+			switch format[j] {
+			case '[':
+				return nil, fmt.Errorf("explicit argument index in %q", format)
+			case '*':
+				return nil, fmt.Errorf("* width or precision in %q", format)
+			}
+			j++
+		}
+		if j == len(format) {
+			return nil, fmt.Errorf("missing verb at the end of %q", format)
+		}
+		if format[j] != '%' {
+			_, size := utf8.DecodeRuneInString(format[j:])
+			verbs = append(verbs, format[i:j+size])
+			j += size - 1
+		}
+		i = j
+	}
+	return verbs, nil
+}
+
+type withTemplate struct {
+	template *Template
+	args     []interface{}
+	fields   Fields
+}
+
+func (w *withTemplate) Error() string { return redact(w.plainMessage()) }
+
+func (w *withTemplate) plainMessage() string {
+	return fmt.Sprintf(w.template.coder.msg, w.args...)
+}
+
+func (w *withTemplate) writePlain(out io.Writer) { _, _ = io.WriteString(out, w.plainMessage()) }
+
+func (w *withTemplate) Code() int      { return w.template.coder.code }
+func (w *withTemplate) Fields() Fields { return w.fields }
+
+// TemplateID returns the ID of the Template of w.
+func (w *withTemplate) TemplateID() string { return w.template.id }
+
+// Is reports whether target is the Template of w.
+func (w *withTemplate) Is(target error) bool {
+	t, ok := target.(*Template)
+	return ok && t == w.template
+}
+
+// As sets target to the Template of w if target is a **Template.
+func (w *withTemplate) As(target interface{}) bool {
+	if p, ok := target.(**Template); ok {
+		*p = w.template
+		return true
+	}
+	return false
+}
+
+func (w *withTemplate) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v', 's':
+		_, _ = io.WriteString(s, w.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", w.Error())
+	}
+}
+
+type templater interface {
+	TemplateID() string
+}
+
+// TemplateOf returns the ID of the Template of the outermost error in err's
+// chain that was created by Template.New, or "" if there is none.
+func TemplateOf(err error) string {
+	var id string
+	walk(err, func(err error) bool {
+		if v, ok := err.(templater); ok {
+			id = v.TemplateID()
+			return true
+		}
+		return false
+	})
+	return id
+}
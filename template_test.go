@@ -0,0 +1,195 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestDefineTemplate(t *testing.T) {
+	tmpl := DefineTemplate("user_not_found", 46001, "user %s not found in tenant %d",
+		[]string{"user", "tenant"}, DefineHTTPStatus(404))
+	defer Unregister(tmpl)
+
+	if tmpl.ID() != "user_not_found" {
+		t.Errorf("id: want: %s, got: %s", "user_not_found", tmpl.ID())
+	}
+	if tmpl.Code() != 46001 {
+		t.Errorf("code: want: %d, got: %d", 46001, tmpl.Code())
+	}
+	if tmpl.HTTPStatus() != 404 {
+		t.Errorf("http status: want: %d, got: %d", 404, tmpl.HTTPStatus())
+	}
+	if tmpl.String() != "user %s not found in tenant %d" {
+		t.Errorf("string: want: %s, got: %s", "user %s not found in tenant %d", tmpl.String())
+	}
+	if got := ParseCoder(tmpl); got != Coder(tmpl) {
+		t.Errorf("ParseCoder: want: %v, got: %v", tmpl, got)
+	}
+}
+
+func TestTemplateNew(t *testing.T) {
+	tmpl := DefineTemplate("user_not_found", 46002, "user %s not found in tenant %d",
+		[]string{"user", "tenant"})
+	defer Unregister(tmpl)
+	other := DefineTemplate("user_expired", 46003, "user %s expired", []string{"user"})
+	defer Unregister(other)
+
+	tests := []struct {
+		err     error
+		message string
+		fields  Fields
+	}{
+		{tmpl.New("alice", 7), "user alice not found in tenant 7", Fields{"user": "alice", "tenant": 7}},
+		{Wrap(tmpl.New("bob", 1), "login"), "login: user bob not found in tenant 1", Fields{"user": "bob", "tenant": 1}},
+	}
+	for i, tt := range tests {
+		if got := tt.err.Error(); got != tt.message {
+			t.Errorf("test %d: Error: want: %s, got: %s", i+1, tt.message, got)
+		}
+		if got := FieldsOf(tt.err); !reflect.DeepEqual(got, tt.fields) {
+			t.Errorf("test %d: FieldsOf: want: %v, got: %v", i+1, tt.fields, got)
+		}
+		if !Is(tt.err, tmpl) {
+			t.Errorf("test %d: Is: want: true, got: false", i+1)
+		}
+		if Is(tt.err, other) {
+			t.Errorf("test %d: Is other: want: false, got: true", i+1)
+		}
+		if got := ParseCoder(tt.err); got != Coder(tmpl) {
+			t.Errorf("test %d: ParseCoder: want: %v, got: %v", i+1, tmpl, got)
+		}
+		if got := TemplateOf(tt.err); got != "user_not_found" {
+			t.Errorf("test %d: TemplateOf: want: %s, got: %s", i+1, "user_not_found", got)
+		}
+		var target *Template
+		if !As(tt.err, &target) || target != tmpl {
+			t.Errorf("test %d: As: want: %v, got: %v", i+1, tmpl, target)
+		}
+	}
+
+	want := "user alice not found in tenant 7\n" +
+		"github.com/shipengqi/errors.TestTemplateNew\n" +
+		"\t.+/template_test.go:45"
+	if got := fmt.Sprintf("%+v", tests[0].err); !regexp.MustCompile(want).MatchString(got) {
+		t.Errorf("%%+v: want: %q, got: %q", want, got)
+	}
+	if got := TemplateOf(New("error")); got != "" {
+		t.Errorf("TemplateOf: want: %q, got: %q", "", got)
+	}
+}
+
+func TestDefineTemplatePanic(t *testing.T) {
+	tests := []struct {
+		format string
+		params []string
+		want   string
+	}{
+		{"user %s in tenant %d", []string{"user"}, "template `bad`: format has 2 verbs but 1 parameters"},
+		{"user %s", []string{"user", "tenant"}, "template `bad`: format has 1 verbs but 2 parameters"},
+		{"user %[1]s", []string{"user"}, `template ` + "`bad`" + `: explicit argument index in "user %[1]s"`},
+		{"user %*d", []string{"user"}, `template ` + "`bad`" + `: * width or precision in "user %*d"`},
+		{"user %", nil, `template ` + "`bad`" + `: missing verb at the end of "user %"`},
+	}
+	for i, tt := range tests {
+		func() {
+			defer func() {
+				if r := recover(); r != tt.want {
+					t.Errorf("test %d: recover: want: %v, got: %v", i+1, tt.want, r)
+				}
+			}()
+			tmpl := DefineTemplate("bad", 46010, tt.format, tt.params)
+			Unregister(tmpl)
+		}()
+	}
+	if _, ok := Lookup(46010); ok {
+		t.Errorf("Lookup: invalid template registered")
+	}
+}
+
+func TestTemplateNewArgs(t *testing.T) {
+	tmpl := DefineTemplate("user_not_found", 46011, "user %-8s in tenant %03d (100%%)",
+		[]string{"user", "tenant"})
+	defer Unregister(tmpl)
+
+	tests := []struct {
+		args    []interface{}
+		message string
+		fields  Fields
+	}{
+		{[]interface{}{"bob", 7}, "user bob      in tenant 007 (100%)", Fields{"user": "bob", "tenant": 7}},
+		{[]interface{}{"bob"}, "user bob      in tenant %!d(MISSING) (100%)", Fields{"user": "bob"}},
+		{[]interface{}{"bob", 7, 3}, "user bob      in tenant 007 (100%)%!(EXTRA int=3)", Fields{"user": "bob", "tenant": 7}},
+		{[]interface{}{"bob", "acme"}, "user bob      in tenant %!d(string=acme) (100%)", Fields{"user": "bob", "tenant": "acme"}},
+		{nil, "user %!s(MISSING) in tenant %!d(MISSING) (100%)", nil},
+	}
+	for i, tt := range tests {
+		err := tmpl.New(tt.args...)
+		if got := err.Error(); got != tt.message {
+			t.Errorf("test %d: Error: want: %q, got: %q", i+1, tt.message, got)
+		}
+		if got := FieldsOf(err); !reflect.DeepEqual(got, tt.fields) {
+			t.Errorf("test %d: FieldsOf: want: %v, got: %v", i+1, tt.fields, got)
+		}
+	}
+}
+
+func TestTemplateUserMessage(t *testing.T) {
+	tmpl := DefineTemplate("user_not_found", 46005, "user %s not found in tenant %d",
+		[]string{"user", "tenant"}, DefineHTTPStatus(404))
+	defer Unregister(tmpl)
+	public := DefineTemplate("user_expired", 46006, "user %s expired in tenant %d",
+		[]string{"user", "tenant"}, DefineMessage("Your account has expired"))
+	defer Unregister(public)
+
+	if got, want := public.String(), "Your account has expired"; got != want {
+		t.Errorf("String: want: %q, got: %q", want, got)
+	}
+	if got, want := public.Error(), "user %s expired in tenant %d"; got != want {
+		t.Errorf("Error: want: %q, got: %q", want, got)
+	}
+
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{tmpl.New("bob", 3), "Internal server error"},
+		{public.New("bob", 3), "Your account has expired"},
+		{Wrap(public.New("bob", 3), "login"), "Your account has expired"},
+	}
+	for i, tt := range tests {
+		if got := PublicMessage(tt.err); got != tt.want {
+			t.Errorf("test %d: PublicMessage: want: %q, got: %q", i+1, tt.want, got)
+		}
+		if got := Message(tt.err, "en"); got != tt.want {
+			t.Errorf("test %d: Message: want: %q, got: %q", i+1, tt.want, got)
+		}
+	}
+}
+
+func TestTemplateMarshalError(t *testing.T) {
+	tmpl := DefineTemplate("user_not_found", 46004, "user %s not found in tenant %d",
+		[]string{"user", "tenant"})
+	defer Unregister(tmpl)
+
+	got, err := MarshalError(tmpl.New("alice", 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v struct {
+		Message  string `json:"message"`
+		Code     int    `json:"code"`
+		Template string `json:"template"`
+		Fields   Fields `json:"fields"`
+	}
+	if err := json.Unmarshal(got, &v); err != nil {
+		t.Fatalf("%v: %s", err, got)
+	}
+	want := Fields{"user": "alice", "tenant": float64(7)}
+	if v.Message != "user alice not found in tenant 7" || v.Code != 46004 ||
+		v.Template != "user_not_found" || !reflect.DeepEqual(v.Fields, want) {
+		t.Errorf("MarshalError: unexpected %s", got)
+	}
+}